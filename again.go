@@ -10,28 +10,94 @@ import (
 type Operation[T any] internal.Operation[T]
 type BackoffConfiguration = internal.BackoffConfiguration
 
+// Attempt describes the current run of an operation, it is available in the context given to the operation.
+type Attempt = internal.Attempt
+
+// AttemptFromContext returns the attempt metadata injected into the context given to Operation.Run,
+// ok is false when the context doesn't come from a retryer.
+func AttemptFromContext(ctx context.Context) (attempt Attempt, ok bool) {
+	return internal.AttemptFromContext(ctx)
+}
+
+// Option customizes the retryer built by WithExponentialBackoff, WithConstantDelay and WithCustomTicksCalculator.
+type Option func(config *internal.RetryerConfig)
+
+// WithTracing creates a runtime/trace task per retry call, with a region for each attempt and backoff sleep,
+// and labels the operation goroutine with the policy name and attempt number for pprof.
+func WithTracing(policyName string) Option {
+	return func(config *internal.RetryerConfig) {
+		config.Name = policyName
+		config.Trace = true
+	}
+}
+
+// WithMaxAttempts stops retrying after attempts runs of the operation, whatever the ticks calculator says.
+// Calculators returned by Policy.NewTicksCalculator stop after the same number of attempts.
+func WithMaxAttempts(attempts int) Option {
+	return func(config *internal.RetryerConfig) {
+		config.MaxAttempts = attempts
+	}
+}
+
+// WithRetryIf only retries errors accepted by retryable, any other error is returned right away.
+// Policy.Retryable applies it for code running attempts itself.
+func WithRetryIf(retryable func(err error) bool) Option {
+	return func(config *internal.RetryerConfig) {
+		config.Retryable = retryable
+	}
+}
+
+// PanicError is a recovered panic, carrying the panic value and the stack of the panicking goroutine.
+type PanicError = internal.PanicError
+
+// PanicMode tells whether recovered panics are retried.
+type PanicMode int
+
+const (
+	// RetryPanics retries operations that panicked like any failing one
+	RetryPanics PanicMode = iota
+	// StopOnPanic returns the PanicError of the first operation that panicked
+	StopOnPanic
+)
+
+// WithPanicRecovery recovers panics of the operation, converting them into a PanicError carrying
+// the panic value and stack trace, retried or returned right away depending on mode.
+func WithPanicRecovery(mode PanicMode) Option {
+	return func(config *internal.RetryerConfig) {
+		config.RecoverPanics = true
+		config.PermanentPanics = mode == StopOnPanic
+	}
+}
+
 // WithExponentialBackoff initialize a retryer using ExponentialBackoff algorithm to calculate delay between each retry.
-func WithExponentialBackoff[T any](configuration BackoffConfiguration) internal.Retryer[T] {
-	return internal.MustRetryer[T](internal.RetryerConfig{
-		TicksCalculator: internal.MustExponentialBackoffTicksCalculator(configuration, systemClock{}),
-		Timer:           &defaultTimer{},
-	})
+func WithExponentialBackoff[T any](configuration BackoffConfiguration, options ...Option) internal.Retryer[T] {
+	return newRetryer[T](internal.MustExponentialBackoffTicksCalculator(configuration, SystemClock{}), options)
 }
 
 // WithConstantDelay initialize a retryer using a constant delay algorithm to calculate delay between each retry.
-func WithConstantDelay[T any](delay, timeout time.Duration) internal.Retryer[T] {
-	return internal.MustRetryer[T](internal.RetryerConfig{
-		TicksCalculator: internal.MustConstantDelayTicksCalculator(delay, timeout, systemClock{}),
-		Timer:           &defaultTimer{},
-	})
+func WithConstantDelay[T any](delay, timeout time.Duration, options ...Option) internal.Retryer[T] {
+	return newRetryer[T](internal.MustConstantDelayTicksCalculator(delay, timeout, SystemClock{}), options)
+}
+
+// WithLinearDelay initialize a retryer waiting initial before the first retry and increment more before every following one.
+func WithLinearDelay[T any](initial, increment, timeout time.Duration, options ...Option) internal.Retryer[T] {
+	return newRetryer[T](internal.MustLinearDelayTicksCalculator(initial, increment, timeout, SystemClock{}), options)
 }
 
 // WithCustomTicksCalculator initialize retryer using custom calculator to calculate delays between retries.
-func WithCustomTicksCalculator[T any](calculator internal.TicksCalculator) internal.Retryer[T] {
-	return internal.MustRetryer[T](internal.RetryerConfig{
+func WithCustomTicksCalculator[T any](calculator internal.TicksCalculator, options ...Option) internal.Retryer[T] {
+	return newRetryer[T](calculator, options)
+}
+
+func newRetryer[T any](calculator internal.TicksCalculator, options []Option) internal.Retryer[T] {
+	config := internal.RetryerConfig{
 		TicksCalculator: calculator,
 		Timer:           &defaultTimer{},
-	})
+	}
+	for _, option := range options {
+		option(&config)
+	}
+	return internal.MustRetryer[T](config)
 }
 
 // RetryOperation use WithExponentialBackoff to retry operation until it stops failing or timeout is reached.
@@ -3,6 +3,7 @@ package again_test
 import (
 	"context"
 	"errors"
+	"runtime/pprof"
 	"testing"
 	"time"
 
@@ -66,7 +67,7 @@ func TestWithConstantDelay(t *testing.T) {
 
 func TestRetryOperation(t *testing.T) {
 	t.Run("given operation is called until permanent error", func(t *testing.T) {
-		testContext := context.Background()
+		testContext := markedContext(context.Background())
 		givenOperation := NewFakeOperation(t)
 
 		givenOperation.
@@ -108,3 +109,71 @@ func TestRetry(t *testing.T) {
 		require.Equal(t, 4, called)
 	})
 }
+
+func TestWithTracing(t *testing.T) {
+	t.Run("operation is labeled with the policy name", func(t *testing.T) {
+		var policy string
+		retryer := again.WithConstantDelay[int](time.Millisecond, time.Second, again.WithTracing("payments-api"))
+
+		_, err := retryer.Retry(context.Background(), runFunc(func(ctx context.Context) (int, error) {
+			policy, _ = pprof.Label(ctx, "again.policy")
+			return 1, nil
+		}))
+		require.NoError(t, err)
+
+		require.Equal(t, "payments-api", policy)
+	})
+}
+
+type runFunc func(ctx context.Context) (int, error)
+
+func (f runFunc) Run(ctx context.Context) (int, error) {
+	return f(ctx)
+}
+
+func TestWithPanicRecovery(t *testing.T) {
+	policy := func(mode again.PanicMode) again.Policy {
+		return again.ConstantDelay(time.Millisecond, time.Second, again.WithPanicRecovery(mode))
+	}
+
+	t.Run("panics are retried", func(t *testing.T) {
+		called := 0
+
+		value, err := again.Get(context.Background(), policy(again.RetryPanics), func(ctx context.Context) (int, error) {
+			called++
+			if called < 3 {
+				panic("flaky")
+			}
+			return called, nil
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, 3, value)
+	})
+
+	t.Run("panic stops retrying", func(t *testing.T) {
+		called := 0
+		cause := errors.New("closed pool")
+
+		err := again.Do(context.Background(), policy(again.StopOnPanic), func(ctx context.Context) error {
+			called++
+			panic(cause)
+		})
+
+		require.Equal(t, 1, called)
+		var panicErr *again.PanicError
+		require.ErrorAs(t, err, &panicErr)
+		require.Equal(t, cause, panicErr.Value)
+		require.ErrorIs(t, err, cause)
+		require.Contains(t, string(panicErr.Stack), "again_test.go")
+	})
+
+	t.Run("last panic is returned when the policy gives up", func(t *testing.T) {
+		err := again.Do(context.Background(), policy(again.RetryPanics).With(again.WithMaxAttempts(2)),
+			func(ctx context.Context) error {
+				panic("always")
+			})
+
+		require.EqualError(t, err, "panic: always")
+	})
+}
@@ -0,0 +1,171 @@
+package again
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBackoffTTL     = 10 * time.Minute
+	defaultBackoffMaxKeys = 10_000
+)
+
+// BackoffManagerConfig Set values for a BackoffManager.
+type BackoffManagerConfig struct {
+	// Policy calculates the delays of every key, its timeout is measured from the first failure of a streak
+	Policy Policy
+	// TTL forgets keys untouched for this long, ten minutes by default. It should be longer than the
+	// policy max delay so backed off keys are not forgotten while waiting
+	TTL time.Duration
+	// MaxKeys bounds the number of tracked keys, the least recently used ones are forgotten first, 10000 by default
+	MaxKeys int
+	// Clock provides the current time, the system clock is used when nil
+	Clock Clock
+}
+
+// BackoffManager tracks a backoff per key across separate calls, so a failing host or tenant is backed off
+// without slowing down the others. It is safe for concurrent use.
+type BackoffManager struct {
+	config BackoffManagerConfig
+
+	mu   sync.Mutex
+	keys map[string]*list.Element
+	// lru orders the keys from the most to the least recently used
+	lru *list.List
+}
+
+type keyBackoff struct {
+	key        string
+	calculator TicksCalculator
+	readyAt    time.Time
+	touchedAt  time.Time
+	// lastDelay keeps backing off the key once its policy stops
+	lastDelay time.Duration
+}
+
+// NewBackoffManager returns a manager without any backed off key.
+func NewBackoffManager(config BackoffManagerConfig) *BackoffManager {
+	if config.TTL <= 0 {
+		config.TTL = defaultBackoffTTL
+	}
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = defaultBackoffMaxKeys
+	}
+	if config.Clock == nil {
+		config.Clock = SystemClock{}
+	}
+
+	return &BackoffManager{
+		config: config,
+		keys:   map[string]*list.Element{},
+		lru:    list.New(),
+	}
+}
+
+// Backoff records a failure for key and returns the tick calculated by its policy, the key is not ready
+// until the tick delay has passed. Once the policy stops, the returned tick has Stop set and the key keeps
+// backing off with the last delay of the policy until Success is called or the key expires.
+func (m *BackoffManager) Backoff(key string) Tick {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.config.Clock.Now()
+	m.evictExpired(now)
+
+	element, ok := m.keys[key]
+	if !ok {
+		element = m.lru.PushFront(&keyBackoff{
+			key:        key,
+			calculator: m.config.Policy.NewTicksCalculator(m.config.Clock),
+		})
+		m.keys[key] = element
+		m.evictOverflow()
+	}
+	m.lru.MoveToFront(element)
+
+	backoff := element.Value.(*keyBackoff)
+	backoff.touchedAt = now
+	tick := backoff.calculator.Next()
+	if tick.Stop {
+		tick.Next = backoff.lastDelay
+	}
+	backoff.lastDelay = tick.Next
+	backoff.readyAt = now.Add(tick.Next)
+
+	return tick
+}
+
+// Success resets the backoff of key.
+func (m *BackoffManager) Success(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.keys[key]; ok {
+		m.remove(element)
+	}
+}
+
+// ReadyAt returns when key can be used again, the zero time when it is not backed off.
+func (m *BackoffManager) ReadyAt(key string) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.evictExpired(m.config.Clock.Now())
+	element, ok := m.keys[key]
+	if !ok {
+		return time.Time{}
+	}
+
+	return element.Value.(*keyBackoff).readyAt
+}
+
+// Wait blocks until key is ready or ctx ends.
+func (m *BackoffManager) Wait(ctx context.Context, key string) error {
+	readyAt := m.ReadyAt(key)
+	delay := readyAt.Sub(m.config.Clock.Now())
+	if readyAt.IsZero() || delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Len returns the number of tracked keys.
+func (m *BackoffManager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.lru.Len()
+}
+
+// evictExpired forgets the keys untouched for longer than TTL, it must be called holding the lock.
+func (m *BackoffManager) evictExpired(now time.Time) {
+	for element := m.lru.Back(); element != nil; element = m.lru.Back() {
+		if now.Sub(element.Value.(*keyBackoff).touchedAt) <= m.config.TTL {
+			return
+		}
+		m.remove(element)
+	}
+}
+
+// evictOverflow forgets the least recently used keys above MaxKeys, it must be called holding the lock.
+func (m *BackoffManager) evictOverflow() {
+	for m.lru.Len() > m.config.MaxKeys {
+		m.remove(m.lru.Back())
+	}
+}
+
+func (m *BackoffManager) remove(element *list.Element) {
+	m.lru.Remove(element)
+	delete(m.keys, element.Value.(*keyBackoff).key)
+}
@@ -0,0 +1,126 @@
+package again_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+)
+
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func (c *manualClock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func givenBackoffManager(clock *manualClock, config again.BackoffManagerConfig) *again.BackoffManager {
+	config.Policy = again.ExponentialBackoff(again.BackoffConfiguration{
+		InitialInterval:      time.Second,
+		IntervalMultiplier:   2,
+		Timeout:              time.Hour,
+		DisableRandomization: true,
+	})
+	config.Clock = clock
+
+	return again.NewBackoffManager(config)
+}
+
+func TestBackoffManager(t *testing.T) {
+	t.Run("keys are backed off independently across calls", func(t *testing.T) {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		manager := givenBackoffManager(clock, again.BackoffManagerConfig{})
+
+		require.Equal(t, time.Second, manager.Backoff("host-a").Next)
+		clock.Add(time.Second)
+		require.Equal(t, 2*time.Second, manager.Backoff("host-a").Next)
+		clock.Add(2 * time.Second)
+		require.Equal(t, 4*time.Second, manager.Backoff("host-a").Next)
+
+		require.Equal(t, clock.now.Add(4*time.Second), manager.ReadyAt("host-a"))
+		require.True(t, manager.ReadyAt("host-b").IsZero())
+	})
+
+	t.Run("success resets the key", func(t *testing.T) {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		manager := givenBackoffManager(clock, again.BackoffManagerConfig{})
+		manager.Backoff("host-a")
+		manager.Backoff("host-a")
+
+		manager.Success("host-a")
+
+		require.True(t, manager.ReadyAt("host-a").IsZero())
+		require.Equal(t, time.Second, manager.Backoff("host-a").Next)
+	})
+
+	t.Run("untouched keys are evicted after ttl", func(t *testing.T) {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		manager := givenBackoffManager(clock, again.BackoffManagerConfig{TTL: time.Minute})
+		manager.Backoff("host-a")
+		clock.Add(30 * time.Second)
+		manager.Backoff("host-b")
+
+		clock.Add(45 * time.Second)
+
+		require.True(t, manager.ReadyAt("host-a").IsZero())
+		require.False(t, manager.ReadyAt("host-b").IsZero())
+		require.Equal(t, 1, manager.Len())
+	})
+
+	t.Run("least recently used keys are evicted above max keys", func(t *testing.T) {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		manager := givenBackoffManager(clock, again.BackoffManagerConfig{MaxKeys: 2})
+		manager.Backoff("host-a")
+		manager.Backoff("host-b")
+		manager.Backoff("host-a")
+
+		manager.Backoff("host-c")
+
+		require.Equal(t, 2, manager.Len())
+		require.True(t, manager.ReadyAt("host-b").IsZero())
+		require.False(t, manager.ReadyAt("host-a").IsZero())
+	})
+
+	t.Run("keys keep backing off after the policy stops", func(t *testing.T) {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		manager := again.NewBackoffManager(again.BackoffManagerConfig{
+			Policy: again.ConstantDelay(10*time.Second, time.Minute),
+			Clock:  clock,
+		})
+
+		var tick again.Tick
+		for i := 0; i < 12; i++ {
+			tick = manager.Backoff("host-a")
+			require.Equal(t, 10*time.Second, tick.Next)
+			require.Equal(t, clock.now.Add(10*time.Second), manager.ReadyAt("host-a"))
+			clock.Add(tick.Next)
+		}
+
+		require.True(t, tick.Stop)
+	})
+
+	t.Run("wait until the key is ready", func(t *testing.T) {
+		manager := again.NewBackoffManager(again.BackoffManagerConfig{
+			Policy: again.ConstantDelay(20*time.Millisecond, time.Minute),
+		})
+		require.NoError(t, manager.Wait(context.Background(), "host-a"))
+		manager.Backoff("host-a")
+
+		startAt := time.Now()
+		require.NoError(t, manager.Wait(context.Background(), "host-a"))
+		require.GreaterOrEqual(t, time.Since(startAt), 15*time.Millisecond)
+
+		manager.Backoff("host-a")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, manager.Wait(ctx, "host-a"), context.Canceled)
+	})
+}
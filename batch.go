@@ -0,0 +1,130 @@
+package again
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jdvr/go-again/internal"
+)
+
+// ErrBatchIncomplete is returned by RetryBatch when some items didn't succeed.
+var ErrBatchIncomplete = errors.New("again: batch incomplete")
+
+// errBatchPending makes the retryer run another attempt with the items still failing.
+var errBatchPending = errors.New("again: batch items pending")
+
+// ItemResult is the outcome of one batch item on an attempt, an error wrapped with NewPermanentError
+// stops retrying the item.
+type ItemResult[R any] struct {
+	Value R
+	Err   error
+}
+
+// BatchFunc processes the pending items returning one result per item in the same order.
+// A non-nil error fails every pending item on this attempt.
+type BatchFunc[I, R any] func(ctx context.Context, items []I) ([]ItemResult[R], error)
+
+// BatchItem is the final state of an item.
+type BatchItem[I, R any] struct {
+	// Index is the position of the item in the original batch
+	Index    int
+	Item     I
+	Value    R
+	Attempts int
+	// Err is the last item error, nil for succeeded items
+	Err error
+}
+
+// BatchReport classifies every item of the batch by its final state, each list is sorted by Index.
+type BatchReport[I, R any] struct {
+	Succeeded []BatchItem[I, R]
+	// Failed items returned a permanent error
+	Failed []BatchItem[I, R]
+	// Exhausted items were still failing when the retry gave up
+	Exhausted []BatchItem[I, R]
+}
+
+// RetryBatch runs items using policy, on every attempt only the items that failed with a non permanent
+// error are run again. It returns ErrBatchIncomplete when some items didn't succeed, wrapping the last batch
+// or context error if any, the report is complete in every case. run is never called for an empty batch.
+func RetryBatch[I, R any](ctx context.Context, policy Policy, items []I, run BatchFunc[I, R]) (BatchReport[I, R], error) {
+	var report BatchReport[I, R]
+	if len(items) == 0 {
+		return report, nil
+	}
+	pending := make([]*BatchItem[I, R], len(items))
+	for i, item := range items {
+		pending[i] = &BatchItem[I, R]{Index: i, Item: item}
+	}
+
+	_, err := newPolicyRetryer[struct{}](policy).Retry(ctx, handleRun(func(ctx context.Context) (struct{}, error) {
+		pendingItems := make([]I, len(pending))
+		for i, item := range pending {
+			pendingItems[i] = item.Item
+			item.Attempts++
+		}
+
+		results, err := run(ctx, pendingItems)
+		if err == nil && len(results) != len(pending) {
+			err = internal.Permanent(fmt.Errorf("again: batch returned %d results for %d items", len(results), len(pending)))
+		}
+		if err != nil {
+			var permanent *internal.PermanentError
+			for _, item := range pending {
+				item.Err = err
+				if errors.As(err, &permanent) {
+					item.Err = permanent.Err
+					report.Failed = append(report.Failed, *item)
+				}
+			}
+			if permanent != nil {
+				pending = nil
+			}
+			return struct{}{}, err
+		}
+
+		stillPending := pending[:0]
+		for i, result := range results {
+			item := pending[i]
+			item.Value = result.Value
+			item.Err = result.Err
+
+			var permanent *internal.PermanentError
+			switch {
+			case result.Err == nil:
+				report.Succeeded = append(report.Succeeded, *item)
+			case errors.As(result.Err, &permanent):
+				item.Err = permanent.Err
+				report.Failed = append(report.Failed, *item)
+			default:
+				stillPending = append(stillPending, item)
+			}
+		}
+		pending = stillPending
+		if len(pending) > 0 {
+			return struct{}{}, errBatchPending
+		}
+
+		return struct{}{}, nil
+	}))
+
+	for _, item := range pending {
+		report.Exhausted = append(report.Exhausted, *item)
+	}
+	for _, list := range [][]BatchItem[I, R]{report.Succeeded, report.Failed, report.Exhausted} {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Index < list[j].Index
+		})
+	}
+
+	switch {
+	case len(report.Failed) == 0 && len(report.Exhausted) == 0:
+		return report, nil
+	case err == nil, errors.Is(err, errBatchPending):
+		return report, ErrBatchIncomplete
+	default:
+		return report, fmt.Errorf("%w: %w", ErrBatchIncomplete, err)
+	}
+}
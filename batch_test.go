@@ -0,0 +1,127 @@
+package again_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+)
+
+func TestRetryBatch(t *testing.T) {
+	policy := again.ConstantDelay(time.Millisecond, time.Second)
+
+	t.Run("only failed items are retried", func(t *testing.T) {
+		var calls [][]int
+		failures := map[int]int{2: 1, 4: 2}
+
+		report, err := again.RetryBatch[int, int](context.Background(), policy, []int{1, 2, 3, 4},
+			func(ctx context.Context, items []int) ([]again.ItemResult[int], error) {
+				calls = append(calls, items)
+				results := make([]again.ItemResult[int], len(items))
+				for i, item := range items {
+					if failures[item] > 0 {
+						failures[item]--
+						results[i].Err = errors.New("unavailable")
+						continue
+					}
+					results[i].Value = item * 10
+				}
+				return results, nil
+			})
+		require.NoError(t, err)
+
+		require.Equal(t, [][]int{{1, 2, 3, 4}, {2, 4}, {4}}, calls)
+		require.Len(t, report.Succeeded, 4)
+		require.Equal(t, again.BatchItem[int, int]{Index: 3, Item: 4, Value: 40, Attempts: 3}, report.Succeeded[3])
+		require.Empty(t, report.Failed)
+		require.Empty(t, report.Exhausted)
+	})
+
+	t.Run("report classifies failed and exhausted items", func(t *testing.T) {
+		shortPolicy := again.ConstantDelay(time.Millisecond, 5*time.Millisecond)
+		invalid := errors.New("invalid")
+
+		report, err := again.RetryBatch[string, bool](context.Background(), shortPolicy, []string{"ok", "invalid", "down"},
+			func(ctx context.Context, items []string) ([]again.ItemResult[bool], error) {
+				results := make([]again.ItemResult[bool], len(items))
+				for i, item := range items {
+					switch item {
+					case "ok":
+						results[i].Value = true
+					case "invalid":
+						results[i].Err = again.NewPermanentError(invalid)
+					default:
+						results[i].Err = errors.New("unavailable")
+					}
+				}
+				return results, nil
+			})
+
+		require.Equal(t, again.ErrBatchIncomplete, err)
+		require.Len(t, report.Succeeded, 1)
+		require.Equal(t, "ok", report.Succeeded[0].Item)
+		require.Len(t, report.Failed, 1)
+		require.Equal(t, invalid, report.Failed[0].Err)
+		require.Equal(t, 1, report.Failed[0].Attempts)
+		require.Len(t, report.Exhausted, 1)
+		require.Equal(t, 2, report.Exhausted[0].Index)
+		require.EqualError(t, report.Exhausted[0].Err, "unavailable")
+		require.Greater(t, report.Exhausted[0].Attempts, 1)
+	})
+
+	t.Run("batch error retries every pending item", func(t *testing.T) {
+		called := 0
+
+		report, err := again.RetryBatch[int, int](context.Background(), policy, []int{1, 2},
+			func(ctx context.Context, items []int) ([]again.ItemResult[int], error) {
+				called++
+				if called == 1 {
+					return nil, errors.New("connection reset")
+				}
+				return make([]again.ItemResult[int], len(items)), nil
+			})
+		require.NoError(t, err)
+
+		require.Len(t, report.Succeeded, 2)
+		require.Equal(t, 2, report.Succeeded[0].Attempts)
+	})
+
+	t.Run("permanent batch error fails every pending item", func(t *testing.T) {
+		expectedErr := errors.New("unauthorized")
+
+		report, err := again.RetryBatch[int, int](context.Background(), policy, []int{1, 2},
+			func(ctx context.Context, items []int) ([]again.ItemResult[int], error) {
+				return nil, again.NewPermanentError(expectedErr)
+			})
+
+		require.ErrorIs(t, err, again.ErrBatchIncomplete)
+		require.ErrorIs(t, err, expectedErr)
+		require.Len(t, report.Failed, 2)
+		require.Equal(t, expectedErr, report.Failed[1].Err)
+	})
+
+	t.Run("results must match pending items", func(t *testing.T) {
+		report, err := again.RetryBatch[int, int](context.Background(), policy, []int{1, 2},
+			func(ctx context.Context, items []int) ([]again.ItemResult[int], error) {
+				return make([]again.ItemResult[int], 1), nil
+			})
+
+		require.ErrorIs(t, err, again.ErrBatchIncomplete)
+		require.Len(t, report.Failed, 2)
+	})
+
+	t.Run("empty batches don't run", func(t *testing.T) {
+		report, err := again.RetryBatch[int, int](context.Background(), policy, nil,
+			func(ctx context.Context, items []int) ([]again.ItemResult[int], error) {
+				t.Fatal("empty batch run")
+				return nil, nil
+			})
+
+		require.NoError(t, err)
+		require.Equal(t, again.BatchReport[int, int]{}, report)
+	})
+}
@@ -0,0 +1,24 @@
+// Command again runs a command until it succeeds, waiting between attempts according to a go-again policy.
+//
+// Usage:
+//
+//	again [flags] -- command [args...]
+//
+// The exit code is the one of the last attempt, 124 when an attempt timed out, 127 when the command
+// couldn't start, 128+n when interrupted by signal n and 2 for invalid flags. Signals are forwarded to the
+// running command, a signal arriving between attempts stops retrying.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr, signals))
+}
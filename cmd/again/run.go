@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jdvr/go-again"
+)
+
+const (
+	exitUsage       = 2
+	exitTimeout     = 124
+	exitCannotStart = 127
+	exitSignalBase  = 128
+)
+
+type options struct {
+	policy         string
+	delay          time.Duration
+	maxDelay       time.Duration
+	multiplier     float64
+	increment      time.Duration
+	noJitter       bool
+	attempts       int
+	timeout        time.Duration
+	attemptTimeout time.Duration
+	retryOn        exitCodes
+	stopOn         exitCodes
+	retryOutput    *regexp.Regexp
+	stopOutput     *regexp.Regexp
+	verbose        bool
+}
+
+// exitCodes is a comma separated list of exit codes flag.
+type exitCodes []int
+
+func (c *exitCodes) String() string {
+	codes := make([]string, len(*c))
+	for i, code := range *c {
+		codes[i] = strconv.Itoa(code)
+	}
+
+	return strings.Join(codes, ",")
+}
+
+func (c *exitCodes) Set(value string) error {
+	for _, field := range strings.Split(value, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return fmt.Errorf("invalid exit code %q", field)
+		}
+		*c = append(*c, code)
+	}
+
+	return nil
+}
+
+func (c exitCodes) contains(code int) bool {
+	for _, candidate := range c {
+		if candidate == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// regexpFlag parses a regular expression flag.
+type regexpFlag struct {
+	re **regexp.Regexp
+}
+
+func (r regexpFlag) String() string {
+	if r.re == nil || *r.re == nil {
+		return ""
+	}
+
+	return (*r.re).String()
+}
+
+func (r regexpFlag) Set(value string) error {
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return err
+	}
+	*r.re = re
+
+	return nil
+}
+
+func parseFlags(args []string, stderr io.Writer) (options, []string, error) {
+	var opts options
+	flags := flag.NewFlagSet("again", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: again [flags] -- command [args...]")
+		flags.PrintDefaults()
+	}
+
+	flags.StringVar(&opts.policy, "policy", "exponential", "delay policy: exponential, constant or linear")
+	flags.DurationVar(&opts.delay, "delay", 500*time.Millisecond, "constant delay, or first delay for exponential and linear policies")
+	flags.DurationVar(&opts.maxDelay, "max-delay", 30*time.Second, "maximum delay of the exponential policy")
+	flags.Float64Var(&opts.multiplier, "multiplier", 1.5, "delay multiplier of the exponential policy")
+	flags.DurationVar(&opts.increment, "increment", 500*time.Millisecond, "delay increment of the linear policy")
+	flags.BoolVar(&opts.noJitter, "no-jitter", false, "disable exponential policy randomization")
+	flags.IntVar(&opts.attempts, "attempts", 0, "maximum number of attempts, 0 means no limit")
+	flags.DurationVar(&opts.timeout, "timeout", time.Minute, "maximum duration of all the attempts")
+	flags.DurationVar(&opts.attemptTimeout, "attempt-timeout", 0, "maximum duration of one attempt, 0 means no limit")
+	flags.Var(&opts.retryOn, "retry-on", "comma separated exit codes to retry, any non zero code by default")
+	flags.Var(&opts.stopOn, "stop-on", "comma separated exit codes that stop retrying")
+	flags.Var(regexpFlag{re: &opts.retryOutput}, "retry-output", "retry when the output matches this regular expression, even on success")
+	flags.Var(regexpFlag{re: &opts.stopOutput}, "stop-output", "stop retrying when the output matches this regular expression")
+	flags.BoolVar(&opts.verbose, "verbose", false, "log every attempt to stderr")
+
+	if err := flags.Parse(args); err != nil {
+		return opts, nil, err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return opts, nil, errors.New("missing command")
+	}
+
+	return opts, flags.Args(), nil
+}
+
+func (o options) newPolicy() (again.Policy, error) {
+	var retryOptions []again.Option
+	if o.attempts > 0 {
+		retryOptions = append(retryOptions, again.WithMaxAttempts(o.attempts))
+	}
+	if o.delay <= 0 || o.timeout <= 0 {
+		return again.Policy{}, errors.New("delay and timeout must be positive")
+	}
+
+	switch o.policy {
+	case "exponential":
+		return again.ExponentialBackoff(again.BackoffConfiguration{
+			InitialInterval:      o.delay,
+			MaxInterval:          o.maxDelay,
+			IntervalMultiplier:   o.multiplier,
+			Timeout:              o.timeout,
+			DisableRandomization: o.noJitter,
+		}, retryOptions...), nil
+	case "constant":
+		return again.ConstantDelay(o.delay, o.timeout, retryOptions...), nil
+	case "linear":
+		return again.LinearDelay(o.delay, o.increment, o.timeout, retryOptions...), nil
+	default:
+		return again.Policy{}, fmt.Errorf("unknown policy %q", o.policy)
+	}
+}
+
+// run retries the command given in args and returns the exit code of the last attempt.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer, signals <-chan os.Signal) int {
+	opts, command, err := parseFlags(args, stderr)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(stderr, "again: %v\n", err)
+		}
+		return exitUsage
+	}
+	policy, err := opts.newPolicy()
+	if err != nil {
+		fmt.Fprintf(stderr, "again: %v\n", err)
+		return exitUsage
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+	ctx, interrupt := context.WithCancel(ctx)
+	defer interrupt()
+
+	child := &child{interrupt: interrupt}
+	stopForwarding := child.forward(signals)
+	defer stopForwarding()
+
+	exitCode := 0
+	err = again.Do(ctx, policy, func(ctx context.Context) error {
+		attempt, _ := again.AttemptFromContext(ctx)
+		if opts.verbose {
+			fmt.Fprintf(stderr, "again: attempt %d: %s\n", attempt.Number, strings.Join(command, " "))
+		}
+
+		var output []byte
+		exitCode, output = child.run(ctx, opts.attemptTimeout, command, stdin, stdout, stderr)
+
+		err := opts.classify(exitCode, output)
+		if child.signaled() {
+			err = again.NewPermanentError(fmt.Errorf("interrupted"))
+		}
+		if err != nil && opts.verbose {
+			fmt.Fprintf(stderr, "again: attempt %d failed: %v\n", attempt.Number, err)
+		}
+
+		return err
+	})
+	if sig := child.interruption(); sig != nil {
+		return signalExitCode(sig)
+	}
+	if err != nil && exitCode == 0 {
+		// the last attempt exited successfully but its output marked it as failed
+		return 1
+	}
+
+	return exitCode
+}
+
+// classify returns nil when the attempt succeeded, a permanent error when it must not be retried.
+func (o options) classify(exitCode int, output []byte) error {
+	if o.stopOutput != nil && o.stopOutput.Match(output) {
+		return again.NewPermanentError(fmt.Errorf("output matches %q", o.stopOutput))
+	}
+	if o.retryOutput != nil && o.retryOutput.Match(output) {
+		return fmt.Errorf("output matches %q", o.retryOutput)
+	}
+
+	switch {
+	case exitCode == 0:
+		return nil
+	case exitCode == exitCannotStart:
+		return again.NewPermanentError(fmt.Errorf("command can't start"))
+	case o.stopOn.contains(exitCode):
+		return again.NewPermanentError(fmt.Errorf("exit code %d", exitCode))
+	case len(o.retryOn) > 0 && !o.retryOn.contains(exitCode):
+		return again.NewPermanentError(fmt.Errorf("exit code %d", exitCode))
+	default:
+		return fmt.Errorf("exit code %d", exitCode)
+	}
+}
+
+// child is the running command, it receives forwarded signals.
+type child struct {
+	mu       sync.Mutex
+	process  *os.Process
+	received os.Signal
+	// interrupt cancels the retry when a signal arrives while no command runs
+	interrupt   context.CancelFunc
+	interrupted os.Signal
+}
+
+// forward sends signals to the running command until the returned function is called,
+// a signal arriving between attempts interrupts the retry.
+func (c *child) forward(signals <-chan os.Signal) func() {
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-signals:
+				c.mu.Lock()
+				c.received = sig
+				if c.process != nil {
+					_ = c.process.Signal(sig)
+				} else {
+					c.interrupted = sig
+					c.interrupt()
+				}
+				c.mu.Unlock()
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+func (c *child) signaled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.received != nil
+}
+
+// interruption returns the signal that interrupted the retry between attempts, if any.
+func (c *child) interruption() os.Signal {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.interrupted
+}
+
+// run runs the command once, streaming its output, and returns its exit code and combined output.
+func (c *child) run(ctx context.Context, timeout time.Duration, command []string, stdin io.Reader, stdout, stderr io.Writer) (int, []byte) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var output bytes.Buffer
+	var outputMu sync.Mutex
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = stdin
+	cmd.Stdout = io.MultiWriter(stdout, &lockedWriter{mu: &outputMu, w: &output})
+	cmd.Stderr = io.MultiWriter(stderr, &lockedWriter{mu: &outputMu, w: &output})
+
+	c.mu.Lock()
+	if err := cmd.Start(); err != nil {
+		c.mu.Unlock()
+		fmt.Fprintf(stderr, "again: %v\n", err)
+		return exitCannotStart, nil
+	}
+	c.process = cmd.Process
+	c.mu.Unlock()
+
+	err := cmd.Wait()
+
+	c.mu.Lock()
+	c.process = nil
+	received := c.received
+	c.mu.Unlock()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0, output.Bytes()
+	case ctx.Err() != nil:
+		return exitTimeout, output.Bytes()
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		return exitErr.ExitCode(), output.Bytes()
+	case received != nil:
+		return signalExitCode(received), output.Bytes()
+	}
+
+	return 1, output.Bytes()
+}
+
+// signalExitCode returns the shell exit code of a process killed by sig.
+func signalExitCode(sig os.Signal) int {
+	if sig, ok := sig.(syscall.Signal); ok {
+		return exitSignalBase + int(sig)
+	}
+
+	return 1
+}
+
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.w.Write(p)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingScript returns a shell script counting its runs in a file and exiting with the given code
+// until it ran succeedAt times, and the function reading the count.
+func countingScript(t *testing.T, failCode, succeedAt int) (string, func() int) {
+	counter := filepath.Join(t.TempDir(), "count")
+	script := `n=$(cat ` + counter + ` 2>/dev/null || echo 0); n=$((n+1)); echo $n > ` + counter + `; ` +
+		`echo "run $n"; [ $n -ge ` + strconv.Itoa(succeedAt) + ` ] || exit ` + strconv.Itoa(failCode)
+
+	return script, func() int {
+		data, err := os.ReadFile(counter)
+		require.NoError(t, err)
+		count, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		require.NoError(t, err)
+		return count
+	}
+}
+
+func runAgain(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(""), &stdout, &stderr, nil)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	t.Run("command is run until it succeeds", func(t *testing.T) {
+		script, runs := countingScript(t, 1, 3)
+
+		code, stdout, _ := runAgain("-policy", "constant", "-delay", "1ms", "--", "sh", "-c", script)
+
+		require.Equal(t, 0, code)
+		require.Equal(t, 3, runs())
+		require.Equal(t, "run 1\nrun 2\nrun 3\n", stdout)
+	})
+
+	t.Run("exit code is the one of the last attempt", func(t *testing.T) {
+		script, runs := countingScript(t, 3, 100)
+
+		code, _, _ := runAgain("-policy", "linear", "-delay", "1ms", "-increment", "1ms", "-attempts", "2", "--", "sh", "-c", script)
+
+		require.Equal(t, 3, code)
+		require.Equal(t, 2, runs())
+	})
+
+	t.Run("stop on exit codes", func(t *testing.T) {
+		script, runs := countingScript(t, 4, 100)
+
+		code, _, _ := runAgain("-delay", "1ms", "-stop-on", "4,5", "--", "sh", "-c", script)
+
+		require.Equal(t, 4, code)
+		require.Equal(t, 1, runs())
+	})
+
+	t.Run("retry only on exit codes", func(t *testing.T) {
+		script, runs := countingScript(t, 5, 100)
+
+		code, _, _ := runAgain("-delay", "1ms", "-retry-on", "1", "--", "sh", "-c", script)
+
+		require.Equal(t, 5, code)
+		require.Equal(t, 1, runs())
+	})
+
+	t.Run("retry when output matches", func(t *testing.T) {
+		script, runs := countingScript(t, 0, 100)
+
+		code, _, _ := runAgain("-policy", "constant", "-delay", "1ms", "-attempts", "3", "-retry-output", "run [12]", "--", "sh", "-c", script)
+
+		require.Equal(t, 0, code)
+		require.Equal(t, 3, runs())
+	})
+
+	t.Run("stop when output matches", func(t *testing.T) {
+		script, runs := countingScript(t, 1, 100)
+
+		code, _, _ := runAgain("-delay", "1ms", "-stop-output", "run 2", "--", "sh", "-c", script)
+
+		require.Equal(t, 1, code)
+		require.Equal(t, 2, runs())
+	})
+
+	t.Run("attempt timeout", func(t *testing.T) {
+		code, _, _ := runAgain("-attempts", "1", "-attempt-timeout", "20ms", "--", "sleep", "5")
+
+		require.Equal(t, 124, code)
+	})
+
+	t.Run("command can't start", func(t *testing.T) {
+		code, _, stderr := runAgain("-delay", "1ms", "--", "/does/not/exist")
+
+		require.Equal(t, 127, code)
+		require.Contains(t, stderr, "/does/not/exist")
+	})
+
+	t.Run("invalid flags", func(t *testing.T) {
+		code, _, _ := runAgain("-policy", "random", "--", "true")
+		require.Equal(t, 2, code)
+
+		code, _, _ = runAgain("-delay", "1ms")
+		require.Equal(t, 2, code)
+
+		code, _, _ = runAgain("-retry-on", "one", "--", "true")
+		require.Equal(t, 2, code)
+	})
+
+	t.Run("signals are forwarded and stop retrying", func(t *testing.T) {
+		signals := make(chan os.Signal, 1)
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			signals <- syscall.SIGTERM
+		}()
+
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), []string{"-delay", "1ms", "--", "sleep", "5"}, strings.NewReader(""), &stdout, &stderr, signals)
+
+		require.Equal(t, 128+int(syscall.SIGTERM), code)
+	})
+
+	t.Run("signals between attempts interrupt the backoff", func(t *testing.T) {
+		signals := make(chan os.Signal, 1)
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			signals <- syscall.SIGINT
+		}()
+
+		var stdout, stderr bytes.Buffer
+		startAt := time.Now()
+		code := run(context.Background(), []string{"-policy", "constant", "-delay", "1500ms", "-verbose", "--", "sh", "-c", "exit 3"},
+			strings.NewReader(""), &stdout, &stderr, signals)
+
+		require.Equal(t, 128+int(syscall.SIGINT), code)
+		require.Less(t, time.Since(startAt), time.Second)
+		require.Equal(t, 1, strings.Count(stderr.String(), "failed"))
+	})
+}
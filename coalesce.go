@@ -0,0 +1,103 @@
+package again
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jdvr/go-again/internal"
+)
+
+// Group coalesces concurrent retries by key: callers asking for a key already being retried wait for the
+// in-flight retry and share its result instead of starting their own. The zero value is ready to use.
+type Group[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*groupCall[T]
+}
+
+type groupCall[T any] struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	callers int
+	value   T
+	err     error
+	// panicked is the recovered panic of the retry, raised again in every waiting caller
+	panicked *internal.PanicError
+}
+
+// Get retries run using policy unless a retry for key is in flight, in which case it waits for that one.
+// shared reports whether the result was given to more than one caller.
+// The retry runs with a context detached from the callers cancellation, keeping the values of the caller
+// that started it. A caller whose ctx ends stops waiting and gets the context error, the retry is only
+// cancelled when every caller has left. A panic of the retry is raised again as a *PanicError in every
+// waiting caller.
+func (g *Group[T]) Get(ctx context.Context, key string, policy Policy, run RunFunc[T]) (value T, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*groupCall[T]{}
+	}
+	call, ok := g.calls[key]
+	if ok {
+		call.callers++
+	} else {
+		call = g.start(ctx, key, policy, run)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.panicked != nil {
+			panic(call.panicked)
+		}
+		g.mu.Lock()
+		shared = call.callers > 1
+		g.mu.Unlock()
+		return call.value, shared, call.err
+	case <-ctx.Done():
+		g.leave(key, call)
+		return value, false, ctx.Err()
+	}
+}
+
+// start runs the retry in background, it must be called holding the lock.
+func (g *Group[T]) start(ctx context.Context, key string, policy Policy, run RunFunc[T]) *groupCall[T] {
+	callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	call := &groupCall[T]{
+		done:    make(chan struct{}),
+		cancel:  cancel,
+		callers: 1,
+	}
+	g.calls[key] = call
+
+	go func() {
+		defer cancel()
+		var panicked error
+		internal.RecoverPanic(&panicked, func() {
+			call.value, call.err = Get[T](callCtx, policy, run)
+		})
+		call.panicked, _ = panicked.(*internal.PanicError)
+
+		g.mu.Lock()
+		if g.calls[key] == call {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+		close(call.done)
+	}()
+
+	return call
+}
+
+// leave cancels the call once it has no caller left, forgetting it so the next caller starts a new retry.
+func (g *Group[T]) leave(key string, call *groupCall[T]) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	call.callers--
+	if call.callers > 0 {
+		return
+	}
+	call.cancel()
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,159 @@
+package again_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+)
+
+func TestGroup_Get(t *testing.T) {
+	policy := again.ConstantDelay(time.Millisecond, time.Second)
+
+	t.Run("concurrent callers share one retry", func(t *testing.T) {
+		var (
+			group   again.Group[string]
+			runs    atomic.Int32
+			release = make(chan struct{})
+			started = make(chan struct{})
+		)
+		run := func(ctx context.Context) (string, error) {
+			if runs.Add(1) == 1 {
+				close(started)
+			}
+			<-release
+			return "value", nil
+		}
+
+		var wg sync.WaitGroup
+		results := make([]string, 10)
+		shared := make([]bool, 10)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				value, isShared, err := group.Get(context.Background(), "key", policy, run)
+				require.NoError(t, err)
+				results[i], shared[i] = value, isShared
+			}(i)
+		}
+		<-started
+		require.Eventually(t, func() bool {
+			return group.Callers("key") == 10
+		}, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		require.EqualValues(t, 1, runs.Load())
+		for i := range results {
+			require.Equal(t, "value", results[i])
+			require.True(t, shared[i])
+		}
+	})
+
+	t.Run("a caller leaving doesn't cancel the shared retry", func(t *testing.T) {
+		var group again.Group[int]
+		release := make(chan struct{})
+		run := func(ctx context.Context) (int, error) {
+			select {
+			case <-release:
+				return 1, nil
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}
+
+		leaving, leave := context.WithCancel(context.Background())
+		leftErr := make(chan error)
+		go func() {
+			_, _, err := group.Get(leaving, "key", policy, run)
+			leftErr <- err
+		}()
+		require.Eventually(t, func() bool {
+			return group.Callers("key") == 1
+		}, time.Second, time.Millisecond)
+		stayed := make(chan int)
+		go func() {
+			value, _, err := group.Get(context.Background(), "key", policy, run)
+			require.NoError(t, err)
+			stayed <- value
+		}()
+		require.Eventually(t, func() bool {
+			return group.Callers("key") == 2
+		}, time.Second, time.Millisecond)
+
+		leave()
+		require.ErrorIs(t, <-leftErr, context.Canceled)
+		close(release)
+		require.Equal(t, 1, <-stayed)
+	})
+
+	t.Run("retry is cancelled when every caller leaves", func(t *testing.T) {
+		var group again.Group[int]
+		running := make(chan struct{})
+		cancelled := make(chan struct{})
+		run := func(ctx context.Context) (int, error) {
+			close(running)
+			<-ctx.Done()
+			close(cancelled)
+			return 0, again.NewPermanentError(ctx.Err())
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-running
+			cancel()
+		}()
+		_, _, err := group.Get(ctx, "key", policy, run)
+
+		require.ErrorIs(t, err, context.Canceled)
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("shared retry not cancelled")
+		}
+	})
+
+	t.Run("panics are raised in the callers", func(t *testing.T) {
+		var group again.Group[int]
+
+		var recovered any
+		func() {
+			defer func() {
+				recovered = recover()
+			}()
+			_, _, _ = group.Get(context.Background(), "key", policy, func(ctx context.Context) (int, error) {
+				panic("nil map")
+			})
+		}()
+
+		var panicErr *again.PanicError
+		require.ErrorAs(t, recovered.(error), &panicErr)
+		require.Equal(t, "nil map", panicErr.Value)
+		require.Zero(t, group.Callers("key"))
+	})
+
+	t.Run("finished retries are not reused", func(t *testing.T) {
+		var group again.Group[int]
+		calls := 0
+		run := func(ctx context.Context) (int, error) {
+			calls++
+			return calls, nil
+		}
+
+		first, shared, err := group.Get(context.Background(), "key", policy, run)
+		require.NoError(t, err)
+		require.False(t, shared)
+		second, _, err := group.Get(context.Background(), "key", policy, run)
+		require.NoError(t, err)
+
+		require.Equal(t, 1, first)
+		require.Equal(t, 2, second)
+		require.Zero(t, group.Callers("key"))
+	})
+}
@@ -0,0 +1,149 @@
+package durable
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	jobFileExtension     = ".json"
+	corruptFileExtension = ".corrupt"
+)
+
+// FileStore keeps one JSON file per job in a directory, files are replaced atomically so a crash
+// never leaves a partially written job.
+type FileStore struct {
+	mu            sync.Mutex
+	dir           string
+	onCorruptFile func(name string, err error)
+}
+
+var _ Store = &FileStore{}
+
+// FileStoreOption customizes a FileStore.
+type FileStoreOption func(store *FileStore)
+
+// OnCorruptFile calls fn with the name of every job file Due can't read and the reason, so it can be reported.
+func OnCorruptFile(fn func(name string, err error)) FileStoreOption {
+	return func(store *FileStore) {
+		store.onCorruptFile = fn
+	}
+}
+
+// NewFileStore returns a FileStore writing into dir, the directory is created if it doesn't exist.
+func NewFileStore(dir string, options ...FileStoreOption) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("durable: create store directory: %w", err)
+	}
+	store := &FileStore{dir: dir}
+	for _, option := range options {
+		option(store)
+	}
+
+	return store, nil
+}
+
+func (s *FileStore) Save(_ context.Context, job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("durable: encode job %q: %w", job.ID, err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".job-*")
+	if err != nil {
+		return fmt.Errorf("durable: save job %q: %w", job.ID, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("durable: save job %q: %w", job.ID, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("durable: save job %q: %w", job.ID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("durable: save job %q: %w", job.ID, err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(job.ID)); err != nil {
+		return fmt.Errorf("durable: save job %q: %w", job.ID, err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrJobNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("durable: delete job %q: %w", id, err)
+	}
+
+	return nil
+}
+
+// Due skips the job files it can't read instead of failing, files that can't be decoded are renamed with
+// a ".corrupt" extension so they are kept for inspection and reported once.
+func (s *FileStore) Due(_ context.Context, now time.Time) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("durable: list jobs: %w", err)
+	}
+
+	var due []Job
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), jobFileExtension) {
+			continue
+		}
+		path := filepath.Join(s.dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			s.corrupt(entry.Name(), fmt.Errorf("durable: read job file %q: %w", entry.Name(), err))
+			continue
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			_ = os.Rename(path, path+corruptFileExtension)
+			s.corrupt(entry.Name(), fmt.Errorf("durable: decode job file %q: %w", entry.Name(), err))
+			continue
+		}
+		if !job.NextDueAt.After(now) {
+			due = append(due, job)
+		}
+	}
+	sortByNextDueAt(due)
+
+	return due, nil
+}
+
+func (s *FileStore) corrupt(name string, err error) {
+	if s.onCorruptFile != nil {
+		s.onCorruptFile(name, err)
+	}
+}
+
+// path encodes the job id so any id is a valid file name.
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, base64.RawURLEncoding.EncodeToString([]byte(id))+jobFileExtension)
+}
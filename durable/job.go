@@ -0,0 +1,37 @@
+// Package durable retries jobs across process restarts by persisting their state in a Store.
+package durable
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrJobNotFound is returned by stores when a job doesn't exist.
+var ErrJobNotFound = errors.New("durable: job not found")
+
+// Job is the persisted state of a retried unit of work.
+type Job struct {
+	// ID identifies the job in the store
+	ID string `json:"id"`
+	// Payload is the opaque data given to the handler
+	Payload []byte `json:"payload"`
+	// Attempts counts the handler runs that already failed
+	Attempts int `json:"attempts"`
+	// CreatedAt is the time the job was enqueued, the policy timeout is measured from it
+	CreatedAt time.Time `json:"created_at"`
+	// NextDueAt is the earliest time the job is run again
+	NextDueAt time.Time `json:"next_due_at"`
+	// LastError is the message of the last handler error
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Store persists jobs, implementations must be safe for concurrent use. A store serves a single Runner.
+type Store interface {
+	// Save inserts or replaces the job with the same ID.
+	Save(ctx context.Context, job Job) error
+	// Delete removes a job, it returns ErrJobNotFound if the job doesn't exist.
+	Delete(ctx context.Context, id string) error
+	// Due returns jobs with NextDueAt before or equal to now sorted by NextDueAt.
+	Due(ctx context.Context, now time.Time) ([]Job, error)
+}
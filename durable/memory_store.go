@@ -0,0 +1,67 @@
+package durable
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps jobs in memory, it doesn't survive restarts and is meant for tests and development.
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]Job
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		jobs: make(map[string]Job),
+	}
+}
+
+func (s *MemoryStore) Save(_ context.Context, job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job.Payload = append([]byte(nil), job.Payload...)
+	s.jobs[job.ID] = job
+
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[id]; !ok {
+		return ErrJobNotFound
+	}
+	delete(s.jobs, id)
+
+	return nil
+}
+
+func (s *MemoryStore) Due(_ context.Context, now time.Time) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []Job
+	for _, job := range s.jobs {
+		if !job.NextDueAt.After(now) {
+			job.Payload = append([]byte(nil), job.Payload...)
+			due = append(due, job)
+		}
+	}
+	sortByNextDueAt(due)
+
+	return due, nil
+}
+
+func sortByNextDueAt(jobs []Job) {
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].NextDueAt.Before(jobs[j].NextDueAt)
+	})
+}
@@ -0,0 +1,188 @@
+package durable
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/jdvr/go-again"
+	"github.com/jdvr/go-again/internal"
+)
+
+const defaultPollInterval = time.Second
+
+// Handler runs a job, returning an error wrapped with again.NewPermanentError stops retrying it.
+type Handler func(ctx context.Context, job Job) error
+
+// RunnerConfig Set values for the durable runner.
+type RunnerConfig struct {
+	// Store persists jobs between runs
+	Store Store
+	// Handler runs every due job
+	Handler Handler
+	// Policy calculates delays between job runs, its timeout is measured from the job creation
+	Policy again.Policy
+	// PollInterval is the delay between checks for due jobs, one second by default
+	PollInterval time.Duration
+	// OnGiveUp is called after a job is removed because of a permanent error or because the policy gave up
+	OnGiveUp func(job Job, err error)
+	// Clock provides the current time, the system clock is used when nil
+	Clock again.Clock
+}
+
+// Runner runs jobs from a Store until they succeed, resuming pending jobs after a restart.
+// A store must be used by a single Runner at a time: jobs are not claimed, so runners sharing a store
+// would run the same jobs and could save a job again after another runner removed it.
+type Runner struct {
+	config RunnerConfig
+}
+
+// MustRunner returns a new Runner or panic if Store or Handler are nil.
+func MustRunner(config RunnerConfig) *Runner {
+	if config.Store == nil {
+		panic("durable: MustRunner: nil Store")
+	}
+	if config.Handler == nil {
+		panic("durable: MustRunner: nil Handler")
+	}
+	if config.PollInterval == 0 {
+		config.PollInterval = defaultPollInterval
+	}
+	if config.Clock == nil {
+		config.Clock = again.SystemClock{}
+	}
+
+	return &Runner{config: config}
+}
+
+// Enqueue persists a new job due immediately, a random id is generated when id is empty.
+func (r *Runner) Enqueue(ctx context.Context, id string, payload []byte) (Job, error) {
+	if id == "" {
+		id = newJobID()
+	}
+	now := r.config.Clock.Now()
+	job := Job{
+		ID:        id,
+		Payload:   payload,
+		CreatedAt: now,
+		NextDueAt: now,
+	}
+
+	return job, r.config.Store.Save(ctx, job)
+}
+
+// Run processes due jobs every PollInterval until ctx is done, it returns the context error
+// or the first store error.
+func (r *Runner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.RunDue(ctx); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs every job due now once and reschedules or removes it depending on the result.
+func (r *Runner) RunDue(ctx context.Context) error {
+	jobs, err := r.config.Store.Due(ctx, r.config.Clock.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.run(ctx, job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Runner) run(ctx context.Context, job Job) error {
+	runErr := r.config.Handler(ctx, job)
+	if runErr == nil {
+		return r.delete(ctx, job.ID)
+	}
+
+	job.Attempts++
+	job.LastError = runErr.Error()
+
+	var permanent *internal.PermanentError
+	if errors.As(runErr, &permanent) {
+		return r.giveUp(ctx, job, permanent.Err)
+	}
+	if !r.config.Policy.Retryable(runErr) {
+		return r.giveUp(ctx, job, runErr)
+	}
+
+	now := r.config.Clock.Now()
+	next := r.nextTick(job, now)
+	if next.Stop {
+		return r.giveUp(ctx, job, runErr)
+	}
+
+	job.NextDueAt = now.Add(next.Next)
+
+	return r.config.Store.Save(ctx, job)
+}
+
+func (r *Runner) giveUp(ctx context.Context, job Job, err error) error {
+	if derr := r.delete(ctx, job.ID); derr != nil {
+		return derr
+	}
+	if r.config.OnGiveUp != nil {
+		r.config.OnGiveUp(job, err)
+	}
+
+	return nil
+}
+
+// delete ignores missing jobs so a job the handler already removed from the store is not an error.
+func (r *Runner) delete(ctx context.Context, id string) error {
+	if err := r.config.Store.Delete(ctx, id); err != nil && !errors.Is(err, ErrJobNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// nextTick rebuilds the policy calculator state from the job, replaying the previous attempts
+// as if they happened at creation time, and asks for the tick after the last failed attempt.
+func (r *Runner) nextTick(job Job, now time.Time) again.Tick {
+	clock := &replayClock{now: job.CreatedAt}
+	calculator := r.config.Policy.NewTicksCalculator(clock)
+	for i := 1; i < job.Attempts; i++ {
+		calculator.Next()
+	}
+	clock.now = now
+
+	return calculator.Next()
+}
+
+type replayClock struct {
+	now time.Time
+}
+
+func (c *replayClock) Now() time.Time {
+	return c.now
+}
+
+func newJobID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
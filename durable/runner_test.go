@@ -0,0 +1,248 @@
+package durable_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+	"github.com/jdvr/go-again/durable"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+var testPolicy = again.ExponentialBackoff(again.BackoffConfiguration{
+	InitialInterval:      time.Second,
+	MaxInterval:          time.Minute,
+	IntervalMultiplier:   2,
+	Timeout:              time.Hour,
+	DisableRandomization: true,
+})
+
+func TestRunner(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successful jobs are removed", func(t *testing.T) {
+		store := durable.NewMemoryStore()
+		var payloads []string
+		runner := durable.MustRunner(durable.RunnerConfig{
+			Store:  store,
+			Policy: testPolicy,
+			Clock:  newFakeClock(),
+			Handler: func(ctx context.Context, job durable.Job) error {
+				payloads = append(payloads, string(job.Payload))
+				return nil
+			},
+		})
+
+		_, err := runner.Enqueue(ctx, "webhook-1", []byte("payload"))
+		require.NoError(t, err)
+		require.NoError(t, runner.RunDue(ctx))
+
+		require.Equal(t, []string{"payload"}, payloads)
+		require.ErrorIs(t, store.Delete(ctx, "webhook-1"), durable.ErrJobNotFound)
+	})
+
+	t.Run("failed jobs are rescheduled using the policy", func(t *testing.T) {
+		store := durable.NewMemoryStore()
+		clock := newFakeClock()
+		runner := durable.MustRunner(durable.RunnerConfig{
+			Store:  store,
+			Policy: testPolicy,
+			Clock:  clock,
+			Handler: func(ctx context.Context, job durable.Job) error {
+				return errors.New("unavailable")
+			},
+		})
+
+		_, err := runner.Enqueue(ctx, "webhook-1", nil)
+		require.NoError(t, err)
+
+		var delays []time.Duration
+		for i := 0; i < 3; i++ {
+			require.NoError(t, runner.RunDue(ctx))
+			due, err := store.Due(ctx, clock.now.Add(time.Hour))
+			require.NoError(t, err)
+			require.Len(t, due, 1)
+			require.Equal(t, i+1, due[0].Attempts)
+			require.Equal(t, "unavailable", due[0].LastError)
+
+			delay := due[0].NextDueAt.Sub(clock.now)
+			delays = append(delays, delay)
+			clock.Advance(delay)
+		}
+
+		require.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, delays)
+	})
+
+	t.Run("pending jobs are resumed after a restart", func(t *testing.T) {
+		dir := t.TempDir()
+		clock := newFakeClock()
+
+		store, err := durable.NewFileStore(dir)
+		require.NoError(t, err)
+		before := durable.MustRunner(durable.RunnerConfig{
+			Store:  store,
+			Policy: testPolicy,
+			Clock:  clock,
+			Handler: func(ctx context.Context, job durable.Job) error {
+				return errors.New("unavailable")
+			},
+		})
+		_, err = before.Enqueue(ctx, "webhook-1", []byte("payload"))
+		require.NoError(t, err)
+		require.NoError(t, before.RunDue(ctx))
+		clock.Advance(time.Second)
+		require.NoError(t, before.RunDue(ctx))
+
+		var resumed []durable.Job
+		reopened, err := durable.NewFileStore(dir)
+		require.NoError(t, err)
+		after := durable.MustRunner(durable.RunnerConfig{
+			Store:  reopened,
+			Policy: testPolicy,
+			Clock:  clock,
+			Handler: func(ctx context.Context, job durable.Job) error {
+				resumed = append(resumed, job)
+				return nil
+			},
+		})
+
+		require.NoError(t, after.RunDue(ctx))
+		require.Empty(t, resumed, "job is not due yet")
+
+		clock.Advance(2 * time.Second)
+		require.NoError(t, after.RunDue(ctx))
+
+		require.Len(t, resumed, 1)
+		require.Equal(t, []byte("payload"), resumed[0].Payload)
+		require.Equal(t, 2, resumed[0].Attempts)
+	})
+
+	t.Run("jobs are given up on permanent errors", func(t *testing.T) {
+		store := durable.NewMemoryStore()
+		expectedErr := errors.New("gone")
+		var gaveUp []error
+		runner := durable.MustRunner(durable.RunnerConfig{
+			Store:  store,
+			Policy: testPolicy,
+			Clock:  newFakeClock(),
+			Handler: func(ctx context.Context, job durable.Job) error {
+				return again.NewPermanentError(expectedErr)
+			},
+			OnGiveUp: func(job durable.Job, err error) {
+				gaveUp = append(gaveUp, err)
+			},
+		})
+
+		_, err := runner.Enqueue(ctx, "webhook-1", nil)
+		require.NoError(t, err)
+		require.NoError(t, runner.RunDue(ctx))
+
+		require.Equal(t, []error{expectedErr}, gaveUp)
+		require.ErrorIs(t, store.Delete(ctx, "webhook-1"), durable.ErrJobNotFound)
+	})
+
+	t.Run("jobs are given up once the policy timeout is reached", func(t *testing.T) {
+		store := durable.NewMemoryStore()
+		clock := newFakeClock()
+		var gaveUp []durable.Job
+		runner := durable.MustRunner(durable.RunnerConfig{
+			Store:  store,
+			Policy: testPolicy,
+			Clock:  clock,
+			Handler: func(ctx context.Context, job durable.Job) error {
+				return errors.New("unavailable")
+			},
+			OnGiveUp: func(job durable.Job, err error) {
+				gaveUp = append(gaveUp, job)
+			},
+		})
+
+		_, err := runner.Enqueue(ctx, "webhook-1", nil)
+		require.NoError(t, err)
+		clock.Advance(2 * time.Hour)
+		require.NoError(t, runner.RunDue(ctx))
+
+		require.Len(t, gaveUp, 1)
+		require.Equal(t, 1, gaveUp[0].Attempts)
+	})
+
+	t.Run("jobs are given up on errors the policy doesn't retry", func(t *testing.T) {
+		store := durable.NewMemoryStore()
+		clock := newFakeClock()
+		var gaveUp []durable.Job
+		runner := durable.MustRunner(durable.RunnerConfig{
+			Store: store,
+			Policy: testPolicy.With(again.WithMaxAttempts(2), again.WithRetryIf(func(err error) bool {
+				return err.Error() == "unavailable"
+			})),
+			Clock: clock,
+			Handler: func(ctx context.Context, job durable.Job) error {
+				if job.ID == "invalid" {
+					return errors.New("invalid")
+				}
+				return errors.New("unavailable")
+			},
+			OnGiveUp: func(job durable.Job, err error) {
+				gaveUp = append(gaveUp, job)
+			},
+		})
+
+		_, err := runner.Enqueue(ctx, "invalid", nil)
+		require.NoError(t, err)
+		_, err = runner.Enqueue(ctx, "unavailable", nil)
+		require.NoError(t, err)
+		require.NoError(t, runner.RunDue(ctx))
+		clock.Advance(time.Minute)
+		require.NoError(t, runner.RunDue(ctx))
+
+		require.Len(t, gaveUp, 2)
+		require.Equal(t, "invalid", gaveUp[0].ID)
+		require.Equal(t, 1, gaveUp[0].Attempts)
+		require.Equal(t, "unavailable", gaveUp[1].ID)
+		require.Equal(t, 2, gaveUp[1].Attempts)
+	})
+
+	t.Run("run stops when the context is done", func(t *testing.T) {
+		runCtx, cancel := context.WithCancel(ctx)
+		runner := durable.MustRunner(durable.RunnerConfig{
+			Store:        durable.NewMemoryStore(),
+			PollInterval: time.Millisecond,
+			Handler: func(ctx context.Context, job durable.Job) error {
+				cancel()
+				return nil
+			},
+		})
+		_, err := runner.Enqueue(ctx, "", nil)
+		require.NoError(t, err)
+
+		require.ErrorIs(t, runner.Run(runCtx), context.Canceled)
+	})
+
+	t.Run("panics for missing dependencies", func(t *testing.T) {
+		require.Panics(t, func() {
+			durable.MustRunner(durable.RunnerConfig{Store: durable.NewMemoryStore()})
+		})
+		require.Panics(t, func() {
+			durable.MustRunner(durable.RunnerConfig{Handler: func(context.Context, durable.Job) error { return nil }})
+		})
+	})
+}
@@ -0,0 +1,123 @@
+package durable_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again/durable"
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) durable.Store{
+		"memory": func(t *testing.T) durable.Store {
+			return durable.NewMemoryStore()
+		},
+		"file": func(t *testing.T) durable.Store {
+			store, err := durable.NewFileStore(t.TempDir())
+			require.NoError(t, err)
+			return store
+		},
+	}
+
+	for name, newStore := range stores {
+		newStore := newStore
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			t.Run("due jobs are sorted by next due time", func(t *testing.T) {
+				store := newStore(t)
+				require.NoError(t, store.Save(ctx, durable.Job{ID: "late", NextDueAt: now}))
+				require.NoError(t, store.Save(ctx, durable.Job{ID: "early", NextDueAt: now.Add(-time.Minute)}))
+				require.NoError(t, store.Save(ctx, durable.Job{ID: "future", NextDueAt: now.Add(time.Minute)}))
+
+				due, err := store.Due(ctx, now)
+				require.NoError(t, err)
+
+				require.Len(t, due, 2)
+				require.Equal(t, "early", due[0].ID)
+				require.Equal(t, "late", due[1].ID)
+			})
+
+			t.Run("save replaces the job with the same id", func(t *testing.T) {
+				store := newStore(t)
+				require.NoError(t, store.Save(ctx, durable.Job{ID: "a/b", Payload: []byte("first"), NextDueAt: now}))
+				require.NoError(t, store.Save(ctx, durable.Job{
+					ID:        "a/b",
+					Payload:   []byte("second"),
+					Attempts:  2,
+					NextDueAt: now,
+					LastError: "boom",
+				}))
+
+				due, err := store.Due(ctx, now)
+				require.NoError(t, err)
+
+				require.Len(t, due, 1)
+				require.Equal(t, []byte("second"), due[0].Payload)
+				require.Equal(t, 2, due[0].Attempts)
+				require.Equal(t, "boom", due[0].LastError)
+			})
+
+			t.Run("delete removes the job", func(t *testing.T) {
+				store := newStore(t)
+				require.NoError(t, store.Save(ctx, durable.Job{ID: "a", NextDueAt: now}))
+
+				require.NoError(t, store.Delete(ctx, "a"))
+				require.ErrorIs(t, store.Delete(ctx, "a"), durable.ErrJobNotFound)
+
+				due, err := store.Due(ctx, now)
+				require.NoError(t, err)
+				require.Empty(t, due)
+			})
+		})
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	t.Run("jobs survive reopening the store", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+		now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		store, err := durable.NewFileStore(dir)
+		require.NoError(t, err)
+		require.NoError(t, store.Save(ctx, durable.Job{ID: "a", Payload: []byte("data"), NextDueAt: now}))
+
+		reopened, err := durable.NewFileStore(dir)
+		require.NoError(t, err)
+		due, err := reopened.Due(ctx, now)
+		require.NoError(t, err)
+
+		require.Len(t, due, 1)
+		require.Equal(t, []byte("data"), due[0].Payload)
+	})
+
+	t.Run("corrupt job files are skipped and reported", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+		now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		var corrupt []string
+		store, err := durable.NewFileStore(dir, durable.OnCorruptFile(func(name string, err error) {
+			corrupt = append(corrupt, name)
+		}))
+		require.NoError(t, err)
+		require.NoError(t, store.Save(ctx, durable.Job{ID: "a", NextDueAt: now}))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "edited.json"), []byte("{"), 0o644))
+
+		due, err := store.Due(ctx, now)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		due, err = store.Due(ctx, now)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+
+		require.Equal(t, []string{"edited.json"}, corrupt)
+		require.FileExists(t, filepath.Join(dir, "edited.json.corrupt"))
+	})
+}
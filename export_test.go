@@ -0,0 +1,13 @@
+package again
+
+// Callers returns the number of callers waiting for the retry in flight for key, 0 when there is none.
+func (g *Group[T]) Callers(key string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if call, ok := g.calls[key]; ok {
+		return call.callers
+	}
+
+	return 0
+}
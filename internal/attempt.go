@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+type attemptContextKey struct{}
+
+// Attempt describes the current run of an operation inside a retry call.
+type Attempt struct {
+	// Number is the attempt index, starting at 1
+	Number int
+	// StartedAt is the time the attempt started
+	StartedAt time.Time
+	// Deadline is the earliest of the context deadline and the ticks calculator timeout, zero if unknown
+	Deadline time.Time
+	// Last is true when the retryer won't run another attempt if this one fails,
+	// it is best effort as calculators only decide to stop once the attempt has failed
+	Last bool
+	// IdempotencyKey is stable across every attempt of the same retry call
+	IdempotencyKey string
+	// PreviousErr is the error returned by the previous attempt, nil for the first one
+	PreviousErr error
+}
+
+// Remaining returns the budget left for this and later attempts at the attempt start, -1 when there is no deadline.
+func (a Attempt) Remaining() time.Duration {
+	if a.Deadline.IsZero() {
+		return -1
+	}
+	remaining := a.Deadline.Sub(a.StartedAt)
+	if remaining < 0 {
+		return 0
+	}
+
+	return remaining
+}
+
+// AttemptFromContext returns the attempt injected by the retryer into the context given to Operation.Run.
+func AttemptFromContext(ctx context.Context) (Attempt, bool) {
+	attempt, ok := ctx.Value(attemptContextKey{}).(Attempt)
+	return attempt, ok
+}
+
+func withAttempt(ctx context.Context, attempt Attempt) context.Context {
+	return context.WithValue(ctx, attemptContextKey{}, attempt)
+}
+
+// deadliner is implemented by ticks calculators with a known time budget.
+type deadliner interface {
+	Deadline() time.Time
+}
+
+// newAttempt describes attempt number given the previous error, deadline is the earliest of the
+// context and the calculator ones.
+func newAttempt(ctx context.Context, calculator TicksCalculator, now time.Time, number int, key string, previousErr error) Attempt {
+	var deadline time.Time
+	if d, ok := calculator.(deadliner); ok {
+		deadline = d.Deadline()
+	}
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+
+	return Attempt{
+		Number:         number,
+		StartedAt:      now,
+		Deadline:       deadline,
+		Last:           !deadline.IsZero() && !now.Before(deadline),
+		IdempotencyKey: key,
+		PreviousErr:    previousErr,
+	}
+}
+
+func newIdempotencyKey() string {
+	key := make([]byte, 16)
+	_, _ = rand.Read(key)
+
+	return hex.EncodeToString(key)
+}
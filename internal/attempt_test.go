@@ -0,0 +1,131 @@
+package internal_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again/internal"
+)
+
+func TestAttemptFromContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("attempt is injected in every run", func(t *testing.T) {
+		t.Parallel()
+		firstErr := errors.New("first")
+		var attempts []internal.Attempt
+		retryer := internal.MustRetryer[int](internal.RetryerConfig{
+			TicksCalculator: &twoTicksCalculator{},
+			Timer:           &instantTimer{},
+		})
+
+		_, err := retryer.Retry(context.Background(), operationFunc[int](func(ctx context.Context) (int, error) {
+			attempt, ok := internal.AttemptFromContext(ctx)
+			require.True(t, ok)
+			attempts = append(attempts, attempt)
+			if len(attempts) == 1 {
+				return 0, firstErr
+			}
+			return 0, errors.New("second")
+		}))
+		require.Error(t, err)
+
+		require.Len(t, attempts, 2)
+		require.Equal(t, 1, attempts[0].Number)
+		require.Equal(t, 2, attempts[1].Number)
+		require.NotEmpty(t, attempts[0].IdempotencyKey)
+		require.Equal(t, attempts[0].IdempotencyKey, attempts[1].IdempotencyKey)
+		require.NoError(t, attempts[0].PreviousErr)
+		require.Equal(t, firstErr, attempts[1].PreviousErr)
+		require.False(t, attempts[1].StartedAt.Before(attempts[0].StartedAt))
+	})
+
+	t.Run("idempotency key changes between retry calls", func(t *testing.T) {
+		t.Parallel()
+		var keys []string
+		retryer := internal.MustRetryer[int](internal.RetryerConfig{
+			TicksCalculator: singleTicksCalculator{},
+			Timer:           &instantTimer{},
+		})
+		operation := operationFunc[int](func(ctx context.Context) (int, error) {
+			attempt, _ := internal.AttemptFromContext(ctx)
+			keys = append(keys, attempt.IdempotencyKey)
+			return 0, nil
+		})
+
+		_, _ = retryer.Retry(context.Background(), operation)
+		_, _ = retryer.Retry(context.Background(), operation)
+
+		require.NotEqual(t, keys[0], keys[1])
+	})
+
+	t.Run("no deadline", func(t *testing.T) {
+		t.Parallel()
+		var attempt internal.Attempt
+		retryer := internal.MustRetryer[int](internal.RetryerConfig{
+			TicksCalculator: singleTicksCalculator{},
+			Timer:           &instantTimer{},
+		})
+
+		_, _ = retryer.Retry(context.Background(), operationFunc[int](func(ctx context.Context) (int, error) {
+			attempt, _ = internal.AttemptFromContext(ctx)
+			return 0, nil
+		}))
+
+		require.True(t, attempt.Deadline.IsZero())
+		require.Equal(t, time.Duration(-1), attempt.Remaining())
+		require.False(t, attempt.Last)
+	})
+
+	t.Run("deadline comes from the calculator timeout", func(t *testing.T) {
+		t.Parallel()
+		var attempt internal.Attempt
+		retryer := internal.MustRetryer[int](internal.RetryerConfig{
+			TicksCalculator: internal.MustConstantDelayTicksCalculator(time.Millisecond, time.Hour, wallClock{}),
+			Timer:           &instantTimer{},
+		})
+
+		_, _ = retryer.Retry(context.Background(), operationFunc[int](func(ctx context.Context) (int, error) {
+			attempt, _ = internal.AttemptFromContext(ctx)
+			return 0, nil
+		}))
+
+		require.InDelta(t, time.Hour, attempt.Remaining(), float64(time.Second))
+		require.False(t, attempt.Last)
+	})
+
+	t.Run("last attempt once the deadline is reached", func(t *testing.T) {
+		t.Parallel()
+		var attempt internal.Attempt
+		givenCtx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		retryer := internal.MustRetryer[int](internal.RetryerConfig{
+			TicksCalculator: internal.MustConstantDelayTicksCalculator(time.Millisecond, time.Hour, wallClock{}),
+			Timer:           &instantTimer{},
+		})
+
+		_, _ = retryer.Retry(givenCtx, operationFunc[int](func(ctx context.Context) (int, error) {
+			attempt, _ = internal.AttemptFromContext(ctx)
+			return 0, nil
+		}))
+
+		require.Equal(t, time.Duration(0), attempt.Remaining())
+		require.True(t, attempt.Last)
+	})
+
+	t.Run("context without attempt", func(t *testing.T) {
+		t.Parallel()
+		_, ok := internal.AttemptFromContext(context.Background())
+		require.False(t, ok)
+	})
+}
+
+type wallClock struct{}
+
+func (w wallClock) Now() time.Time {
+	return time.Now()
+}
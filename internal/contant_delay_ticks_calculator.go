@@ -9,10 +9,10 @@ type constantDelayTicksCalculator struct {
 	timeout time.Duration
 
 	startAt time.Time
-	clock   clock
+	clock   Clock
 }
 
-func MustConstantDelayTicksCalculator(delay time.Duration, timeout time.Duration, clock clock) TicksCalculator {
+func MustConstantDelayTicksCalculator(delay time.Duration, timeout time.Duration, clock Clock) TicksCalculator {
 	if delay == 0 || timeout == 0 {
 		panic("delay and timeout must be set")
 	}
@@ -39,3 +39,8 @@ func (c *constantDelayTicksCalculator) Next() Tick {
 func (c *constantDelayTicksCalculator) Reset() {
 	c.startAt = c.clock.Now()
 }
+
+// Deadline returns the time after which the calculator stops.
+func (c *constantDelayTicksCalculator) Deadline() time.Time {
+	return c.startAt.Add(c.timeout)
+}
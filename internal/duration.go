@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Duration is a time.Duration encoded as a human readable string such as "500ms" or "2m",
+// a plain number of nanoseconds is accepted as well.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText accepts any time.ParseDuration string or an integer number of nanoseconds.
+func (d *Duration) UnmarshalText(text []byte) error {
+	if nanoseconds, err := strconv.ParseInt(string(text), 10, 64); err == nil {
+		*d = Duration(nanoseconds)
+		return nil
+	}
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", text, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// UnmarshalJSON accepts a duration string or a number of nanoseconds, as encoded by time.Duration.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return d.UnmarshalText(data)
+	}
+	return d.UnmarshalText([]byte(text))
+}
+
+// backoffConfigurationText mirrors BackoffConfiguration with human readable durations for decoding.
+type backoffConfigurationText struct {
+	InitialInterval      Duration `json:"initialInterval,omitempty" yaml:"initialInterval,omitempty"`
+	MaxInterval          Duration `json:"maxInterval,omitempty" yaml:"maxInterval,omitempty"`
+	IntervalMultiplier   float64  `json:"intervalMultiplier,omitempty" yaml:"intervalMultiplier,omitempty"`
+	Timeout              Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	DisableRandomization bool     `json:"disableRandomization,omitempty" yaml:"disableRandomization,omitempty"`
+}
+
+// UnmarshalJSON decodes human readable durations as well as the nanoseconds json.Marshal encodes,
+// field names are matched case-insensitively so "timeout" and "Timeout" are both accepted.
+func (c *BackoffConfiguration) UnmarshalJSON(data []byte) error {
+	var text backoffConfigurationText
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	*c = BackoffConfiguration{
+		InitialInterval:      time.Duration(text.InitialInterval),
+		MaxInterval:          time.Duration(text.MaxInterval),
+		IntervalMultiplier:   text.IntervalMultiplier,
+		Timeout:              time.Duration(text.Timeout),
+		DisableRandomization: text.DisableRandomization,
+	}
+	return nil
+}
@@ -18,23 +18,27 @@ const (
 	defaultTimeout         = 1 * time.Minute
 )
 
-//clock is a time wrapper
-type clock interface {
+// Clock is a time wrapper
+type Clock interface {
 	Now() time.Time
 }
 
+// Random returns the pseudo-random numbers in [0.0,1.0) used for jitter, rand.Float64 fits.
+type Random func() float64
+
 // BackoffConfiguration Set values for backoff algorithm configurable parameters.
+// It is encoded in JSON and YAML with human readable durations such as "500ms" or "2m".
 type BackoffConfiguration struct {
 	// InitialInterval delay before the first retry
-	InitialInterval time.Duration
+	InitialInterval time.Duration `yaml:"initialInterval,omitempty"`
 	// MaxInterval delay between retries, once it reaches it stop increasing
-	MaxInterval time.Duration
+	MaxInterval time.Duration `yaml:"maxInterval,omitempty"`
 	// IntervalMultiplier set the base interval to multiply the period delay
-	IntervalMultiplier float64
+	IntervalMultiplier float64 `yaml:"intervalMultiplier,omitempty"`
 	// Timeout define the max duration of the retry process
-	Timeout time.Duration
+	Timeout time.Duration `yaml:"timeout,omitempty"`
 	// DisableRandomization generate predicable exponential backoff intervals
-	DisableRandomization bool
+	DisableRandomization bool `yaml:"disableRandomization,omitempty"`
 }
 
 type exponentialBackoffTicksCalculator struct {
@@ -43,18 +47,29 @@ type exponentialBackoffTicksCalculator struct {
 	currentDelay time.Duration
 	startTime    time.Time
 
-	clock clock
+	clock  Clock
+	random Random
 }
 
 var _ TicksCalculator = &exponentialBackoffTicksCalculator{}
 
-func MustExponentialBackoffTicksCalculator(configuration BackoffConfiguration, clock clock) *exponentialBackoffTicksCalculator {
+func MustExponentialBackoffTicksCalculator(configuration BackoffConfiguration, clock Clock) *exponentialBackoffTicksCalculator {
+	return MustExponentialBackoffTicksCalculatorWithRandom(configuration, clock, rand.Float64)
+}
+
+// MustExponentialBackoffTicksCalculatorWithRandom returns a calculator taking its jitter from random,
+// the global math/rand source is used when random is nil.
+func MustExponentialBackoffTicksCalculatorWithRandom(configuration BackoffConfiguration, clock Clock, random Random) *exponentialBackoffTicksCalculator {
+	if random == nil {
+		random = rand.Float64
+	}
+
 	return &exponentialBackoffTicksCalculator{
 		Configuration: fillWithDefault(configuration),
 		startTime:     clock.Now(),
 		clock:         clock,
+		random:        random,
 	}
-
 }
 
 func fillWithDefault(configuration BackoffConfiguration) BackoffConfiguration {
@@ -100,7 +115,7 @@ func (c *exponentialBackoffTicksCalculator) Next() Tick {
 		if current == 0 {
 			current = c.Configuration.InitialInterval
 		}
-		next = getRandomValueFromInterval(randomizationFactor, rand.Float64(), current)
+		next = getRandomValueFromInterval(randomizationFactor, c.random(), current)
 	}
 
 	c.currentDelay = c.nextDelay()
@@ -148,3 +163,8 @@ func getRandomValueFromInterval(randomizationFactor, random float64, currentInte
 	// we want a 33% chance for selecting either 1, 2 or 3.
 	return time.Duration(minInterval + (random * (maxInterval - minInterval + 1)))
 }
+
+// Deadline returns the time after which the calculator stops.
+func (c *exponentialBackoffTicksCalculator) Deadline() time.Time {
+	return c.startTime.Add(c.Configuration.Timeout)
+}
@@ -0,0 +1,198 @@
+// Package fakesql is a scriptable database/sql driver used to test wrappers without a real database.
+package fakesql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Rows is the result of a scripted query.
+type Rows struct {
+	Columns []string
+	Values  [][]driver.Value
+}
+
+// Driver answers every call with the matching function, nil functions succeed.
+// Calls records every call in order, like "connect", "ping", "query SELECT 1", "begin" or "commit".
+type Driver struct {
+	OnConnect  func() error
+	OnPing     func() error
+	OnQuery    func(query string, args []driver.NamedValue) (*Rows, error)
+	OnExec     func(query string, args []driver.NamedValue) (driver.Result, error)
+	OnBegin    func() error
+	OnCommit   func() error
+	OnRollback func() error
+
+	mu    sync.Mutex
+	calls []string
+}
+
+var (
+	_ driver.Driver        = &Driver{}
+	_ driver.DriverContext = &Driver{}
+)
+
+// Calls returns the recorded calls.
+func (d *Driver) Calls() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string(nil), d.calls...)
+}
+
+func (d *Driver) record(call string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.calls = append(d.calls, call)
+}
+
+func (d *Driver) Open(string) (driver.Conn, error) {
+	return d.Connect(context.Background())
+}
+
+func (d *Driver) OpenConnector(string) (driver.Connector, error) {
+	return d, nil
+}
+
+// Connect makes the driver its own connector.
+func (d *Driver) Connect(context.Context) (driver.Conn, error) {
+	d.record("connect")
+	if d.OnConnect != nil {
+		if err := d.OnConnect(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &conn{driver: d}, nil
+}
+
+func (d *Driver) Driver() driver.Driver {
+	return d
+}
+
+type conn struct {
+	driver *Driver
+}
+
+var (
+	_ driver.Conn               = &conn{}
+	_ driver.ConnBeginTx        = &conn{}
+	_ driver.QueryerContext     = &conn{}
+	_ driver.ExecerContext      = &conn{}
+	_ driver.Pinger             = &conn{}
+	_ driver.NamedValueChecker  = &conn{}
+	_ driver.ConnPrepareContext = &conn{}
+)
+
+func (c *conn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakesql: prepared statements are not supported")
+}
+
+func (c *conn) PrepareContext(context.Context, string) (driver.Stmt, error) {
+	return nil, errors.New("fakesql: prepared statements are not supported")
+}
+
+func (c *conn) Close() error {
+	c.driver.record("close")
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.driver.record("begin")
+	if c.driver.OnBegin != nil {
+		if err := c.driver.OnBegin(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &tx{driver: c.driver}, nil
+}
+
+func (c *conn) Ping(context.Context) error {
+	c.driver.record("ping")
+	if c.driver.OnPing != nil {
+		return c.driver.OnPing()
+	}
+
+	return nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.record("query " + query)
+	result := &Rows{}
+	if c.driver.OnQuery != nil {
+		var err error
+		if result, err = c.driver.OnQuery(query, args); err != nil {
+			return nil, err
+		}
+	}
+
+	return &rows{rows: result}, nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.driver.record("exec " + query)
+	if c.driver.OnExec != nil {
+		return c.driver.OnExec(query, args)
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+// CheckNamedValue accepts any argument.
+func (c *conn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+type tx struct {
+	driver *Driver
+}
+
+func (t *tx) Commit() error {
+	t.driver.record("commit")
+	if t.driver.OnCommit != nil {
+		return t.driver.OnCommit()
+	}
+
+	return nil
+}
+
+func (t *tx) Rollback() error {
+	t.driver.record("rollback")
+	if t.driver.OnRollback != nil {
+		return t.driver.OnRollback()
+	}
+
+	return nil
+}
+
+type rows struct {
+	rows *Rows
+	next int
+}
+
+func (r *rows) Columns() []string {
+	return r.rows.Columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows.Values) {
+		return io.EOF
+	}
+	copy(dest, r.rows.Values[r.next])
+	r.next++
+
+	return nil
+}
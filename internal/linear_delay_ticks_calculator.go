@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"time"
+)
+
+type linearDelayTicksCalculator struct {
+	initial   time.Duration
+	increment time.Duration
+	timeout   time.Duration
+	ticks     int
+	startAt   time.Time
+	clock     Clock
+}
+
+// MustLinearDelayTicksCalculator returns a calculator waiting initial before the first retry and increment
+// more before every following one, it panics if initial or timeout are not set.
+func MustLinearDelayTicksCalculator(initial, increment, timeout time.Duration, clock Clock) TicksCalculator {
+	if initial == 0 || timeout == 0 {
+		panic("initial delay and timeout must be set")
+	}
+	return &linearDelayTicksCalculator{
+		initial:   initial,
+		increment: increment,
+		timeout:   timeout,
+		startAt:   clock.Now(),
+		clock:     clock,
+	}
+}
+
+func (c *linearDelayTicksCalculator) Next() Tick {
+	elapsed := c.clock.Now().Sub(c.startAt)
+	if elapsed > c.timeout {
+		return Tick{Stop: true}
+	}
+	next := c.initial + time.Duration(c.ticks)*c.increment
+	c.ticks++
+
+	return Tick{
+		Next: next,
+		Stop: false,
+	}
+}
+
+func (c *linearDelayTicksCalculator) Reset() {
+	c.startAt = c.clock.Now()
+	c.ticks = 0
+}
+
+// Deadline returns the time after which the calculator stops.
+func (c *linearDelayTicksCalculator) Deadline() time.Time {
+	return c.startAt.Add(c.timeout)
+}
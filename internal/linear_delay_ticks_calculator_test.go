@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinearDelayTicksCalculator_Next(t *testing.T) {
+	t.Run("delay grows by the increment", func(t *testing.T) {
+		ticksCalculator := MustLinearDelayTicksCalculator(100*time.Millisecond, 50*time.Millisecond, 1*time.Hour, defaultClock{})
+		expected := []Tick{
+			{Next: 100 * time.Millisecond},
+			{Next: 150 * time.Millisecond},
+			{Next: 200 * time.Millisecond},
+			{Next: 250 * time.Millisecond},
+		}
+
+		var generated []Tick
+		for i := 0; i < len(expected); i++ {
+			generated = append(generated, ticksCalculator.Next())
+		}
+
+		require.Equal(t, expected, generated)
+	})
+
+	t.Run("reset starts from the initial delay", func(t *testing.T) {
+		ticksCalculator := MustLinearDelayTicksCalculator(100*time.Millisecond, 50*time.Millisecond, 1*time.Hour, defaultClock{})
+		ticksCalculator.Next()
+		ticksCalculator.Next()
+
+		ticksCalculator.Reset()
+
+		require.Equal(t, Tick{Next: 100 * time.Millisecond}, ticksCalculator.Next())
+	})
+
+	t.Run("stop when timed out", func(t *testing.T) {
+		ticksCalculator := MustLinearDelayTicksCalculator(500*time.Millisecond, time.Second, 1*time.Nanosecond, defaultClock{})
+		time.Sleep(time.Millisecond)
+
+		require.Equal(t, Tick{Stop: true}, ticksCalculator.Next())
+	})
+
+	t.Run("panics for 0 config", func(t *testing.T) {
+		require.Panics(t, func() {
+			MustLinearDelayTicksCalculator(0, time.Second, time.Second, defaultClock{})
+		})
+		require.Panics(t, func() {
+			MustLinearDelayTicksCalculator(time.Second, time.Second, 0, defaultClock{})
+		})
+	})
+}
@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"time"
+)
+
+type maxAttemptsTicksCalculator struct {
+	calculator  TicksCalculator
+	maxAttempts int
+	ticks       int
+}
+
+// LimitAttempts returns a calculator stopping once maxAttempts attempts were made, that is on the
+// maxAttempts-th call to Next, and delegating to calculator otherwise.
+func LimitAttempts(calculator TicksCalculator, maxAttempts int) TicksCalculator {
+	return &maxAttemptsTicksCalculator{
+		calculator:  calculator,
+		maxAttempts: maxAttempts,
+	}
+}
+
+func (c *maxAttemptsTicksCalculator) Next() Tick {
+	c.ticks++
+	if c.ticks >= c.maxAttempts {
+		return Tick{Stop: true}
+	}
+
+	return c.calculator.Next()
+}
+
+func (c *maxAttemptsTicksCalculator) Reset() {
+	c.ticks = 0
+	c.calculator.Reset()
+}
+
+// Deadline returns the deadline of the limited calculator, if any.
+func (c *maxAttemptsTicksCalculator) Deadline() time.Time {
+	if d, ok := c.calculator.(deadliner); ok {
+		return d.Deadline()
+	}
+
+	return time.Time{}
+}
@@ -42,15 +42,39 @@ func NewFakeOperation(t *testing.T) *FakeOperation {
 }
 
 func (currentFakeOperator *FakeOperation) Run(context context.Context) (int, error) {
-	expectedCall, ok := currentFakeOperator.expectedCalls[context]
+	expectedCall, ok := currentFakeOperator.expectedCalls[currentFakeOperator.givenContextFor(context)]
 	require.True(currentFakeOperator.t, ok, "Unexpected call for FakeOperation")
 	currentFakeOperator.called = append(currentFakeOperator.called, expectedCall)
 	currentFakeOperator.times += 1
 	return expectedCall.value, expectedCall.err
 }
 
+// givenContextKey marks the contexts given to a FakeOperation.
+type givenContextKey struct{}
+
+// markedContext returns a context a FakeOperation recognizes in the contexts derived from it.
+func markedContext(parent context.Context) context.Context {
+	return context.WithValue(parent, givenContextKey{}, new(int))
+}
+
+// givenContextFor returns the given context a call is for. Retryers run the operation with a context derived
+// from the given one to inject the attempt, it still carries the marker of the given context.
+func (currentFakeOperator *FakeOperation) givenContextFor(ctx context.Context) context.Context {
+	marker := ctx.Value(givenContextKey{})
+	if marker == nil {
+		return ctx
+	}
+	for given := range currentFakeOperator.expectedCalls {
+		if given.Value(givenContextKey{}) == marker {
+			return given
+		}
+	}
+	return ctx
+}
+
 func (currentFakeOperator *FakeOperation) givenContext(ctx context.Context) inputCall {
 	require.NotNil(currentFakeOperator.t, ctx)
+	require.NotNil(currentFakeOperator.t, ctx.Value(givenContextKey{}), "given contexts must be created with markedContext")
 	return inputCall{
 		ctx:           ctx,
 		fakeOperation: currentFakeOperator,
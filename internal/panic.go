@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is a recovered panic.
+type PanicError struct {
+	// Value given to panic
+	Value any
+	// Stack of the panicking goroutine
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value when it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// RecoverPanic runs fn and converts a panic into a PanicError assigned to err.
+func RecoverPanic(err *error, fn func()) {
+	defer func() {
+		if value := recover(); value != nil {
+			*err = &PanicError{Value: value, Stack: debug.Stack()}
+		}
+	}()
+
+	fn()
+}
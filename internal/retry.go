@@ -26,6 +26,11 @@ type TicksCalculator interface {
 	Reset()
 }
 
+// Limiter makes attempts wait for their turn, Wait returns an error when the attempt can't be made before ctx ends.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
 type Retryer[T any] interface {
 	Retry(ctx context.Context, operation Operation[T]) (T, error)
 }
@@ -33,11 +38,40 @@ type Retryer[T any] interface {
 type defaultRetryer[T any] struct {
 	TicksCalculator TicksCalculator
 	Timer           Timer
+	Clock           Clock
+	MaxAttempts     int
+	Retryable       func(err error) bool
+	Limiter         Limiter
+	RecoverPanics   bool
+	PermanentPanics bool
+	Stats           *Stats
+	tracer          tracer
 }
 
 type RetryerConfig struct {
 	TicksCalculator TicksCalculator
 	Timer           Timer
+	// Clock provides attempts start time, the system clock is used when nil.
+	Clock Clock
+	// MaxAttempts stops retrying after this number of attempts, 0 means no limit.
+	MaxAttempts int
+	// Retryable classifies operation errors, errors it rejects stop retrying as permanent ones. Every error
+	// is retried when nil.
+	Retryable func(err error) bool
+	// Limiter is waited on before every attempt, attempts are not limited when nil.
+	Limiter Limiter
+	// RecoverPanics converts operation panics into PanicError, retried like any other error
+	// unless PermanentPanics is set.
+	RecoverPanics   bool
+	PermanentPanics bool
+	// Stats is filled with the statistics of the retry call when not nil, the retryer must then
+	// not be used concurrently.
+	Stats *Stats
+	// Name identifies the retry policy in traces and profiles.
+	Name string
+	// Trace creates a runtime/trace task per retry call, a region per attempt and backoff sleep,
+	// and applies pprof labels with Name and the attempt number while the operation runs.
+	Trace bool
 }
 
 // MustRetryer returns a new Retryer or panic if any dependency is nil.
@@ -48,9 +82,20 @@ func MustRetryer[T any](config RetryerConfig) Retryer[T] {
 	if config.TicksCalculator == nil {
 		panic("again: MustRetryer: nil TicksCalculator")
 	}
+	if config.Clock == nil {
+		config.Clock = systemClock{}
+	}
 	return defaultRetryer[T]{
 		TicksCalculator: config.TicksCalculator,
 		Timer:           config.Timer,
+		Clock:           config.Clock,
+		MaxAttempts:     config.MaxAttempts,
+		Retryable:       config.Retryable,
+		Limiter:         config.Limiter,
+		RecoverPanics:   config.RecoverPanics,
+		PermanentPanics: config.PermanentPanics,
+		Stats:           config.Stats,
+		tracer:          newTracer(config),
 	}
 }
 
@@ -61,37 +106,109 @@ func (retryer defaultRetryer[T]) Retry(ctx context.Context, operation Operation[
 		retryer.Timer.Stop()
 	}()
 
+	ctx, endTask := retryer.tracer.task(ctx)
+	defer endTask()
+
+	stats := newStatsRecorder(retryer.Stats, retryer.Clock)
 	retryer.TicksCalculator.Reset()
-	for {
-		var (
-			value T
-			err   error
-		)
-		value, err = operation.Run(ctx)
+	idempotencyKey := newIdempotencyKey()
+	var (
+		previousErr error
+		value       T
+	)
+	for attempt := 1; ; attempt++ {
+		var err error
+		if retryer.Limiter != nil {
+			waitStartedAt := stats.now()
+			endWait := retryer.tracer.rateLimit(ctx)
+			err = retryer.Limiter.Wait(ctx)
+			endWait()
+			stats.rateLimitWait(waitStartedAt)
+			if err != nil {
+				if ctx.Err() != nil {
+					stats.stop(StopContextDone)
+				} else {
+					stats.stop(StopRateLimited)
+				}
+				return value, err
+			}
+		}
+
+		current := newAttempt(ctx, retryer.TicksCalculator, retryer.Clock.Now(), attempt, idempotencyKey, previousErr)
+		current.Last = current.Last || attempt == retryer.MaxAttempts
+		retryer.tracer.attempt(withAttempt(ctx, current), attempt, func(ctx context.Context) {
+			value, err = retryer.run(ctx, operation)
+		})
+		stats.attempt(current.StartedAt, err)
 		if err == nil {
+			stats.stop(StopSucceeded)
 			return value, nil
 		}
 
 		var permanent *PermanentError
 		if errors.As(err, &permanent) {
+			stats.stop(StopPermanentError)
 			return value, permanent.Err
 		}
 
+		if retryer.Retryable != nil && !retryer.Retryable(err) {
+			stats.stop(StopNotRetryable)
+			return value, err
+		}
+
+		if attempt == retryer.MaxAttempts {
+			stats.stop(StopMaxAttempts)
+			return value, err
+		}
+
 		if next = retryer.TicksCalculator.Next(); next.Stop {
 			if cerr := ctx.Err(); cerr != nil {
+				stats.stop(StopContextDone)
 				return value, cerr
 			}
+			stats.stop(StopPolicy)
 			return value, err
 		}
 
+		previousErr = err
+
+		sleepStartedAt := stats.now()
+		endBackoff := retryer.tracer.backoff(ctx)
 		retryer.Timer.Start(next)
 
 		select {
 		case <-ctx.Done():
+			endBackoff()
+			stats.sleep(sleepStartedAt)
+			stats.stop(StopContextDone)
 			return value, ctx.Err()
 		case <-retryer.Timer.Wait():
 		}
+		endBackoff()
+		stats.sleep(sleepStartedAt)
+	}
+}
+
+// run runs the operation, recovering its panics when configured to.
+func (retryer defaultRetryer[T]) run(ctx context.Context, operation Operation[T]) (value T, err error) {
+	if !retryer.RecoverPanics {
+		return operation.Run(ctx)
+	}
+
+	RecoverPanic(&err, func() {
+		value, err = operation.Run(ctx)
+	})
+	if _, panicked := err.(*PanicError); panicked && retryer.PermanentPanics {
+		err = Permanent(err)
 	}
+
+	return value, err
+}
+
+type systemClock struct{}
+
+func (sc systemClock) Now() time.Time {
+	return time.Now()
 }
 
 type PermanentError struct {
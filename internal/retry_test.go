@@ -17,7 +17,7 @@ func TestRetryer_Retry(t *testing.T) {
 		t.Parallel()
 
 		givenFakeOperation := NewFakeOperation(t)
-		givenCtx := context.TODO()
+		givenCtx := markedContext(context.TODO())
 
 		givenFakeOperation.
 			givenContext(givenCtx).
@@ -38,7 +38,7 @@ func TestRetryer_Retry(t *testing.T) {
 		t.Parallel()
 
 		givenFakeOperation := NewFakeOperation(t)
-		givenCtx := context.TODO()
+		givenCtx := markedContext(context.TODO())
 
 		expectedError := errors.New("whatever")
 
@@ -60,7 +60,7 @@ func TestRetryer_Retry(t *testing.T) {
 		t.Parallel()
 
 		givenFakeOperation := NewFakeOperation(t)
-		givenCtx, cancel := context.WithCancel(context.TODO())
+		givenCtx, cancel := context.WithCancel(markedContext(context.TODO()))
 
 		givenFakeOperation.
 			givenContext(givenCtx).
@@ -81,7 +81,7 @@ func TestRetryer_Retry(t *testing.T) {
 		t.Parallel()
 
 		givenFakeOperation := NewFakeOperation(t)
-		givenCtx := context.Background()
+		givenCtx := markedContext(context.Background())
 
 		givenFakeOperation.
 			givenContext(givenCtx).
@@ -102,7 +102,7 @@ func TestRetryer_Retry(t *testing.T) {
 		t.Parallel()
 
 		givenFakeOperation := NewFakeOperation(t)
-		givenCtx := context.TODO()
+		givenCtx := markedContext(context.TODO())
 
 		givenFakeOperation.
 			givenContext(givenCtx).
@@ -122,7 +122,7 @@ func TestRetryer_Retry(t *testing.T) {
 		t.Parallel()
 
 		givenFakeOperation := NewFakeOperation(t)
-		givenCtx := context.TODO()
+		givenCtx := markedContext(context.TODO())
 
 		anyError := errors.New("any error")
 
@@ -140,6 +140,28 @@ func TestRetryer_Retry(t *testing.T) {
 		require.ErrorIs(t, err, anyError)
 		givenFakeOperation.haveBeenCalled(2)
 	})
+
+	t.Run("operation is executed up to max attempts", func(t *testing.T) {
+		t.Parallel()
+		givenFakeOperation := NewFakeOperation(t)
+		givenCtx := markedContext(context.TODO())
+		anyError := errors.New("any error")
+
+		givenFakeOperation.
+			givenContext(givenCtx).
+			Returns(0, anyError)
+
+		retrayer := internal.MustRetryer[int](internal.RetryerConfig{
+			TicksCalculator: &constantTicksCalculator{},
+			Timer:           &instantTimer{},
+			MaxAttempts:     3,
+		})
+
+		_, err := retrayer.Retry(givenCtx, givenFakeOperation)
+		require.ErrorIs(t, err, anyError)
+
+		givenFakeOperation.haveBeenCalled(3)
+	})
 }
 
 func TestPermanentError(t *testing.T) {
@@ -241,3 +263,11 @@ func (ticksCalculator *twoTicksCalculator) Next() internal.Tick {
 func (ticksCalculator *twoTicksCalculator) Reset() {
 	ticksCalculator.called = 0
 }
+
+type constantTicksCalculator struct{}
+
+func (s constantTicksCalculator) Next() internal.Tick {
+	return internal.Tick{Next: 1 * time.Millisecond, Stop: false}
+}
+
+func (s constantTicksCalculator) Reset() {}
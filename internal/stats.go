@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+)
+
+// StopReason tells why a retry call returned.
+type StopReason int
+
+const (
+	// StopSucceeded means the operation succeeded
+	StopSucceeded StopReason = iota
+	// StopPermanentError means the operation returned a permanent error
+	StopPermanentError
+	// StopNotRetryable means the operation error was rejected by the retryable classifier
+	StopNotRetryable
+	// StopMaxAttempts means the max number of attempts was reached
+	StopMaxAttempts
+	// StopPolicy means the ticks calculator gave up
+	StopPolicy
+	// StopContextDone means the context ended
+	StopContextDone
+	// StopRateLimited means the rate limiter couldn't let the next attempt through
+	StopRateLimited
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case StopSucceeded:
+		return "succeeded"
+	case StopPermanentError:
+		return "permanent error"
+	case StopNotRetryable:
+		return "not retryable"
+	case StopMaxAttempts:
+		return "max attempts"
+	case StopPolicy:
+		return "policy gave up"
+	case StopContextDone:
+		return "context done"
+	case StopRateLimited:
+		return "rate limited"
+	default:
+		return fmt.Sprintf("StopReason(%d)", int(r))
+	}
+}
+
+// AttemptStats describes a finished attempt.
+type AttemptStats struct {
+	// Duration of the operation run
+	Duration time.Duration
+	// Err returned by the operation, nil on success
+	Err error
+}
+
+// Stats describes a finished retry call.
+type Stats struct {
+	// Attempts is the number of times the operation ran
+	Attempts int
+	// Elapsed is the duration of the whole retry call
+	Elapsed time.Duration
+	// Sleep is the time spent in backoff between attempts
+	Sleep time.Duration
+	// RateLimitWait is the time spent waiting for the rate limiter, not included in Sleep
+	RateLimitWait time.Duration
+	// PerAttempt has the duration and error of every attempt
+	PerAttempt []AttemptStats
+	// StopReason tells why the retry call returned
+	StopReason StopReason
+}
+
+// statsRecorder fills stats when not nil and does nothing otherwise.
+type statsRecorder struct {
+	stats     *Stats
+	clock     Clock
+	startedAt time.Time
+}
+
+func newStatsRecorder(stats *Stats, clock Clock) statsRecorder {
+	recorder := statsRecorder{stats: stats, clock: clock}
+	recorder.startedAt = recorder.now()
+	return recorder
+}
+
+func (r statsRecorder) now() time.Time {
+	if r.stats == nil {
+		return time.Time{}
+	}
+	return r.clock.Now()
+}
+
+func (r statsRecorder) attempt(startedAt time.Time, err error) {
+	if r.stats == nil {
+		return
+	}
+	r.stats.Attempts++
+	r.stats.PerAttempt = append(r.stats.PerAttempt, AttemptStats{Duration: r.clock.Now().Sub(startedAt), Err: err})
+}
+
+func (r statsRecorder) sleep(startedAt time.Time) {
+	if r.stats == nil {
+		return
+	}
+	r.stats.Sleep += r.clock.Now().Sub(startedAt)
+}
+
+func (r statsRecorder) rateLimitWait(startedAt time.Time) {
+	if r.stats == nil {
+		return
+	}
+	r.stats.RateLimitWait += r.clock.Now().Sub(startedAt)
+}
+
+func (r statsRecorder) stop(reason StopReason) {
+	if r.stats == nil {
+		return
+	}
+	r.stats.StopReason = reason
+	r.stats.Elapsed = r.clock.Now().Sub(r.startedAt)
+}
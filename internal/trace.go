@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"context"
+	"runtime/pprof"
+	"runtime/trace"
+	"strconv"
+)
+
+const (
+	traceTaskType      = "again.Retry"
+	traceAttemptType   = "again.attempt"
+	traceBackoffType   = "again.backoff"
+	traceRateLimitType = "again.ratelimit"
+	pprofPolicyLabel   = "again.policy"
+	pprofAttemptLabel  = "again.attempt"
+	defaultPolicyName  = "default"
+)
+
+// tracer instruments a retry call with runtime/trace tasks and regions and pprof labels.
+// A disabled tracer does nothing, so the retry loop pays no cost unless tracing is requested.
+type tracer struct {
+	enabled bool
+	name    string
+}
+
+func newTracer(config RetryerConfig) tracer {
+	name := config.Name
+	if name == "" {
+		name = defaultPolicyName
+	}
+	return tracer{
+		enabled: config.Trace,
+		name:    name,
+	}
+}
+
+// task starts a trace task covering the whole retry call, the returned function ends it.
+func (t tracer) task(ctx context.Context) (context.Context, func()) {
+	if !t.enabled {
+		return ctx, func() {}
+	}
+	ctx, task := trace.NewTask(ctx, traceTaskType)
+	trace.Log(ctx, pprofPolicyLabel, t.name)
+	return ctx, task.End
+}
+
+// attempt runs fn inside a trace region with the policy name and attempt number as pprof labels,
+// labels are applied to the calling goroutine, which is the one running the operation.
+func (t tracer) attempt(ctx context.Context, attempt int, fn func(context.Context)) {
+	if !t.enabled {
+		fn(ctx)
+		return
+	}
+	defer trace.StartRegion(ctx, traceAttemptType).End()
+	labels := pprof.Labels(pprofPolicyLabel, t.name, pprofAttemptLabel, strconv.Itoa(attempt))
+	pprof.Do(ctx, labels, fn)
+}
+
+// backoff starts a trace region for a sleep between attempts, the returned function ends it.
+func (t tracer) backoff(ctx context.Context) func() {
+	if !t.enabled {
+		return func() {}
+	}
+	return trace.StartRegion(ctx, traceBackoffType).End
+}
+
+// rateLimit starts a trace region for a wait on the rate limiter before an attempt, the returned function ends it.
+func (t tracer) rateLimit(ctx context.Context) func() {
+	if !t.enabled {
+		return func() {}
+	}
+	return trace.StartRegion(ctx, traceRateLimitType).End
+}
@@ -0,0 +1,80 @@
+package internal_test
+
+import (
+	"context"
+	"errors"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again/internal"
+)
+
+type operationFunc[T any] func(ctx context.Context) (T, error)
+
+func (f operationFunc[T]) Run(ctx context.Context) (T, error) {
+	return f(ctx)
+}
+
+func TestRetryer_Trace(t *testing.T) {
+	t.Run("operation goroutine is labeled with policy and attempt", func(t *testing.T) {
+		var (
+			policies []string
+			attempts []string
+		)
+		retryer := internal.MustRetryer[int](internal.RetryerConfig{
+			TicksCalculator: &twoTicksCalculator{},
+			Timer:           &instantTimer{},
+			Name:            "payments-api",
+			Trace:           true,
+		})
+
+		_, err := retryer.Retry(context.Background(), operationFunc[int](func(ctx context.Context) (int, error) {
+			policy, _ := pprof.Label(ctx, "again.policy")
+			attempt, _ := pprof.Label(ctx, "again.attempt")
+			policies = append(policies, policy)
+			attempts = append(attempts, attempt)
+			return 0, errors.New("any")
+		}))
+		require.Error(t, err)
+
+		require.Equal(t, []string{"payments-api", "payments-api"}, policies)
+		require.Equal(t, []string{"1", "2"}, attempts)
+	})
+
+	t.Run("default policy name is used when name is empty", func(t *testing.T) {
+		var policy string
+		retryer := internal.MustRetryer[int](internal.RetryerConfig{
+			TicksCalculator: singleTicksCalculator{},
+			Timer:           &instantTimer{},
+			Trace:           true,
+		})
+
+		_, err := retryer.Retry(context.Background(), operationFunc[int](func(ctx context.Context) (int, error) {
+			policy, _ = pprof.Label(ctx, "again.policy")
+			return 1, nil
+		}))
+		require.NoError(t, err)
+
+		require.Equal(t, "default", policy)
+	})
+
+	t.Run("no labels when trace is disabled", func(t *testing.T) {
+		found := true
+		retryer := internal.MustRetryer[int](internal.RetryerConfig{
+			TicksCalculator: singleTicksCalculator{},
+			Timer:           &instantTimer{},
+			Name:            "payments-api",
+		})
+
+		_, err := retryer.Retry(context.Background(), operationFunc[int](func(ctx context.Context) (int, error) {
+			_, found = pprof.Label(ctx, "again.policy")
+			return 1, nil
+		}))
+		require.NoError(t, err)
+
+		require.False(t, found)
+	})
+
+}
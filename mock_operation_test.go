@@ -45,7 +45,8 @@ func NewFakeOperation(t *testing.T) *FakeOperation {
 }
 
 func (currentFakeOperator *FakeOperation) Run(context context.Context) (int, error) {
-	expectedCalls, ok := currentFakeOperator.expectedCalls[context]
+	givenCtx := currentFakeOperator.givenContextFor(context)
+	expectedCalls, ok := currentFakeOperator.expectedCalls[givenCtx]
 	require.True(
 		currentFakeOperator.t,
 		ok || currentFakeOperator.allowAnyCall,
@@ -60,15 +61,39 @@ func (currentFakeOperator *FakeOperation) Run(context context.Context) (int, err
 		require.NotZero(currentFakeOperator.t, expectedCalls)
 		call := expectedCalls[0]
 		expectedCall = call
-		currentFakeOperator.expectedCalls[context] = expectedCalls[1:]
+		currentFakeOperator.expectedCalls[givenCtx] = expectedCalls[1:]
 	}
 	currentFakeOperator.called = append(currentFakeOperator.called, expectedCall)
 	currentFakeOperator.times += 1
 	return expectedCall.value, expectedCall.err
 }
 
+// givenContextKey marks the contexts given to a FakeOperation.
+type givenContextKey struct{}
+
+// markedContext returns a context a FakeOperation recognizes in the contexts derived from it.
+func markedContext(parent context.Context) context.Context {
+	return context.WithValue(parent, givenContextKey{}, new(int))
+}
+
+// givenContextFor returns the given context a call is for. Retryers run the operation with a context derived
+// from the given one to inject the attempt, it still carries the marker of the given context.
+func (currentFakeOperator *FakeOperation) givenContextFor(ctx context.Context) context.Context {
+	marker := ctx.Value(givenContextKey{})
+	if marker == nil {
+		return ctx
+	}
+	for given := range currentFakeOperator.expectedCalls {
+		if given.Value(givenContextKey{}) == marker {
+			return given
+		}
+	}
+	return ctx
+}
+
 func (currentFakeOperator *FakeOperation) givenContext(ctx context.Context) inputCall {
 	require.NotNil(currentFakeOperator.t, ctx)
+	require.NotNil(currentFakeOperator.t, ctx.Value(givenContextKey{}), "given contexts must be created with markedContext")
 	return inputCall{
 		ctx:           ctx,
 		fakeOperation: currentFakeOperator,
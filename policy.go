@@ -0,0 +1,164 @@
+package again
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jdvr/go-again/internal"
+)
+
+// Clock provides the current time to ticks calculators.
+type Clock = internal.Clock
+
+// Tick is the delay returned by a TicksCalculator before the next retry.
+type Tick = internal.Tick
+
+// TicksCalculator provides delays for the retryer to wait between retries.
+type TicksCalculator = internal.TicksCalculator
+
+// Random returns the pseudo-random numbers in [0.0,1.0) used to jitter delays, rand.Float64 fits.
+type Random = internal.Random
+
+// Policy describes how an operation is retried without binding it to a result type, so one configured policy
+// serves every call site. A Policy is immutable and safe for concurrent use: every call gets its own
+// ticks calculator and timer. The zero value retries using ExponentialBackoff with default configuration.
+type Policy struct {
+	newTicksCalculator func(clock Clock, random Random) TicksCalculator
+	options            []Option
+	// resolve returns the policy used at call time, see Registry.Live
+	resolve func() Policy
+}
+
+// ExponentialBackoff returns a policy using ExponentialBackoff algorithm to calculate delay between each retry.
+func ExponentialBackoff(configuration BackoffConfiguration, options ...Option) Policy {
+	return Policy{
+		newTicksCalculator: func(clock Clock, random Random) TicksCalculator {
+			return internal.MustExponentialBackoffTicksCalculatorWithRandom(configuration, clock, random)
+		},
+		options: options,
+	}
+}
+
+// ConstantDelay returns a policy using a constant delay algorithm to calculate delay between each retry.
+// It panics if delay or timeout are not set.
+func ConstantDelay(delay, timeout time.Duration, options ...Option) Policy {
+	internal.MustConstantDelayTicksCalculator(delay, timeout, SystemClock{})
+
+	return NewPolicy(func(clock Clock) TicksCalculator {
+		return internal.MustConstantDelayTicksCalculator(delay, timeout, clock)
+	}, options...)
+}
+
+// LinearDelay returns a policy waiting initial before the first retry and increment more before every following one.
+// It panics if initial or timeout are not set.
+func LinearDelay(initial, increment, timeout time.Duration, options ...Option) Policy {
+	internal.MustLinearDelayTicksCalculator(initial, increment, timeout, SystemClock{})
+
+	return NewPolicy(func(clock Clock) TicksCalculator {
+		return internal.MustLinearDelayTicksCalculator(initial, increment, timeout, clock)
+	}, options...)
+}
+
+// NewPolicy returns a policy using a custom calculator, newTicksCalculator is called once per retry call
+// so calculators don't need to be safe for concurrent use.
+func NewPolicy(newTicksCalculator func(clock Clock) TicksCalculator, options ...Option) Policy {
+	return Policy{
+		newTicksCalculator: func(clock Clock, _ Random) TicksCalculator {
+			return newTicksCalculator(clock)
+		},
+		options: options,
+	}
+}
+
+// With returns a copy of the policy with extra options applied after the existing ones.
+func (p Policy) With(options ...Option) Policy {
+	merged := make([]Option, 0, len(p.options)+len(options))
+	merged = append(merged, p.options...)
+	merged = append(merged, options...)
+
+	return Policy{
+		newTicksCalculator: p.newTicksCalculator,
+		options:            merged,
+		resolve:            p.resolve,
+	}
+}
+
+// current returns the policy to use for a new call, resolving live policies.
+func (p Policy) current() Policy {
+	if p.resolve == nil {
+		return p
+	}
+
+	return p.resolve().current().With(p.options...)
+}
+
+// NewTicksCalculator returns a fresh calculator for this policy reading time from clock,
+// the system clock is used when clock is nil. The calculator stops after the attempts allowed by
+// WithMaxAttempts, so code scheduling attempts itself honours them.
+func (p Policy) NewTicksCalculator(clock Clock) TicksCalculator {
+	return p.NewTicksCalculatorWithRandom(clock, nil)
+}
+
+// NewTicksCalculatorWithRandom is NewTicksCalculator taking the jitter of ExponentialBackoff from random,
+// so a seeded source gives reproducible delays. The global math/rand source is used when random is nil,
+// calculators given to NewPolicy ignore it.
+func (p Policy) NewTicksCalculatorWithRandom(clock Clock, random Random) TicksCalculator {
+	p = p.current()
+	if clock == nil {
+		clock = SystemClock{}
+	}
+	var calculator TicksCalculator
+	if p.newTicksCalculator == nil {
+		calculator = internal.MustExponentialBackoffTicksCalculatorWithRandom(BackoffConfiguration{}, clock, random)
+	} else {
+		calculator = p.newTicksCalculator(clock, random)
+	}
+	if maxAttempts := p.retryerConfig().MaxAttempts; maxAttempts > 0 {
+		calculator = internal.LimitAttempts(calculator, maxAttempts)
+	}
+
+	return calculator
+}
+
+// Retryable reports whether the policy retries err: permanent errors and errors rejected by WithRetryIf are not.
+func (p Policy) Retryable(err error) bool {
+	var permanent *internal.PermanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	retryable := p.current().retryerConfig().Retryable
+
+	return retryable == nil || retryable(err)
+}
+
+// retryerConfig returns the retryer settings of the policy options.
+func (p Policy) retryerConfig() internal.RetryerConfig {
+	var config internal.RetryerConfig
+	for _, option := range p.options {
+		option(&config)
+	}
+
+	return config
+}
+
+func newPolicyRetryer[T any](policy Policy) internal.Retryer[T] {
+	policy = policy.current()
+	return newRetryer[T](policy.NewTicksCalculator(nil), policy.options)
+}
+
+// Do retries run using policy until it stops failing, returns a permanent error or the policy gives up.
+// it might return the last run error or a context cancelled error.
+func Do(ctx context.Context, policy Policy, run func(ctx context.Context) error) error {
+	_, err := Get[struct{}](ctx, policy, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, run(ctx)
+	})
+
+	return err
+}
+
+// Get retries run using policy until it stops failing, returns a permanent error or the policy gives up.
+// it might return the last run value and error or a context cancelled error.
+func Get[T any](ctx context.Context, policy Policy, run RunFunc[T]) (T, error) {
+	return newPolicyRetryer[T](policy).Retry(ctx, handleRun(run))
+}
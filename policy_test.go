@@ -0,0 +1,218 @@
+package again_test
+
+import (
+	"context"
+	"errors"
+	"runtime/pprof"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+)
+
+func TestDo(t *testing.T) {
+	t.Run("given function is called until it stops failing", func(t *testing.T) {
+		called := 0
+		policy := again.ConstantDelay(time.Millisecond, time.Second)
+
+		err := again.Do(context.Background(), policy, func(ctx context.Context) error {
+			called++
+			if called < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 3, called)
+	})
+
+	t.Run("given function is called until permanent error", func(t *testing.T) {
+		called := 0
+		expectedErr := errors.New("whatever")
+		policy := again.ConstantDelay(time.Millisecond, time.Second)
+
+		err := again.Do(context.Background(), policy, func(ctx context.Context) error {
+			called++
+			return again.NewPermanentError(expectedErr)
+		})
+		require.ErrorIs(t, err, expectedErr)
+
+		require.Equal(t, 1, called)
+	})
+
+	t.Run("zero policy uses default exponential backoff", func(t *testing.T) {
+		err := again.Do(context.Background(), again.Policy{}, func(ctx context.Context) error {
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestGet(t *testing.T) {
+	t.Run("same policy serves different result types", func(t *testing.T) {
+		policy := again.ExponentialBackoff(again.BackoffConfiguration{
+			InitialInterval: time.Millisecond,
+			Timeout:         time.Second,
+		})
+
+		number, err := again.Get[int](context.Background(), policy, func(ctx context.Context) (int, error) {
+			return 7, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 7, number)
+
+		text, err := again.Get[string](context.Background(), policy, func(ctx context.Context) (string, error) {
+			return "seven", nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, "seven", text)
+	})
+
+	t.Run("policy is safe for concurrent use", func(t *testing.T) {
+		policy := again.ConstantDelay(time.Millisecond, time.Second)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				called := 0
+				value, err := again.Get[int](context.Background(), policy, func(ctx context.Context) (int, error) {
+					called++
+					if called < 2 {
+						return 0, errors.New("not yet")
+					}
+					return called, nil
+				})
+				require.NoError(t, err)
+				require.Equal(t, 2, value)
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestPolicy_With(t *testing.T) {
+	t.Run("options are applied to every call", func(t *testing.T) {
+		base := again.ConstantDelay(time.Millisecond, time.Second)
+		traced := base.With(again.WithTracing("payments-api"))
+
+		var policy string
+		err := again.Do(context.Background(), traced, func(ctx context.Context) error {
+			policy, _ = pprof.Label(ctx, "again.policy")
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, "payments-api", policy)
+
+		err = again.Do(context.Background(), base, func(ctx context.Context) error {
+			policy, _ = pprof.Label(ctx, "again.policy")
+			return nil
+		})
+		require.NoError(t, err)
+		require.Empty(t, policy)
+	})
+}
+
+func TestPolicy_Retryable(t *testing.T) {
+	policy := again.ConstantDelay(time.Millisecond, time.Second, again.WithRetryIf(func(err error) bool {
+		return err.Error() == "unavailable"
+	}))
+
+	require.True(t, policy.Retryable(errors.New("unavailable")))
+	require.False(t, policy.Retryable(errors.New("invalid")))
+	require.False(t, policy.Retryable(again.NewPermanentError(errors.New("unavailable"))))
+	require.True(t, again.Policy{}.Retryable(errors.New("invalid")))
+}
+
+func TestPolicy_NewTicksCalculatorWithRandom(t *testing.T) {
+	t.Run("jitter is taken from random", func(t *testing.T) {
+		policy := again.ExponentialBackoff(again.BackoffConfiguration{
+			InitialInterval: time.Second,
+			Timeout:         time.Minute,
+		})
+
+		calculator := policy.NewTicksCalculatorWithRandom(nil, func() float64 { return 0 })
+
+		require.Equal(t, 500*time.Millisecond, calculator.Next().Next)
+	})
+}
+
+func TestConstantDelay(t *testing.T) {
+	t.Run("panics for 0 config", func(t *testing.T) {
+		require.Panics(t, func() {
+			again.ConstantDelay(0, time.Second)
+		})
+	})
+}
+
+func TestAttemptFromContext(t *testing.T) {
+	t.Run("operation knows the attempt it is running", func(t *testing.T) {
+		var numbers []int
+		policy := again.ConstantDelay(time.Millisecond, time.Second)
+
+		err := again.Do(context.Background(), policy, func(ctx context.Context) error {
+			attempt, ok := again.AttemptFromContext(ctx)
+			require.True(t, ok)
+			numbers = append(numbers, attempt.Number)
+			if attempt.Number < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, []int{1, 2, 3}, numbers)
+	})
+}
+
+func TestLinearDelay(t *testing.T) {
+	t.Run("given function is called until it stops failing", func(t *testing.T) {
+		called := 0
+		policy := again.LinearDelay(time.Millisecond, time.Millisecond, time.Second)
+
+		err := again.Do(context.Background(), policy, func(ctx context.Context) error {
+			called++
+			if called < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 3, called)
+	})
+}
+
+func TestWithMaxAttempts(t *testing.T) {
+	t.Run("given function is called up to max attempts", func(t *testing.T) {
+		var attempts []again.Attempt
+		policy := again.ConstantDelay(time.Millisecond, time.Second, again.WithMaxAttempts(2))
+
+		err := again.Do(context.Background(), policy, func(ctx context.Context) error {
+			attempt, _ := again.AttemptFromContext(ctx)
+			attempts = append(attempts, attempt)
+			return errors.New("always")
+		})
+		require.EqualError(t, err, "always")
+
+		require.Len(t, attempts, 2)
+		require.False(t, attempts[0].Last)
+		require.True(t, attempts[1].Last)
+	})
+
+	t.Run("ticks calculators stop after max attempts", func(t *testing.T) {
+		calculator := again.ConstantDelay(time.Millisecond, time.Hour, again.WithMaxAttempts(3)).NewTicksCalculator(nil)
+
+		require.False(t, calculator.Next().Stop)
+		require.False(t, calculator.Next().Stop)
+		require.True(t, calculator.Next().Stop)
+
+		calculator.Reset()
+		require.False(t, calculator.Next().Stop)
+	})
+}
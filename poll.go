@@ -0,0 +1,76 @@
+package again
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jdvr/go-again/internal"
+)
+
+// ErrConditionNotMet is returned by Poll when the policy gives up or the context ends before the condition is met.
+var ErrConditionNotMet = errors.New("again: condition not met")
+
+// errConditionFalse makes the retryer keep polling while the condition is not met.
+var errConditionFalse = errors.New("again: condition is false")
+
+// Condition reports whether the awaited state has been reached.
+type Condition func(ctx context.Context) (done bool, err error)
+
+// PollOption customizes Poll.
+type PollOption func(config *pollConfig)
+
+type pollConfig struct {
+	tolerateErrors bool
+}
+
+// TolerateErrors keeps polling when the condition returns an error instead of stopping,
+// errors wrapped with NewPermanentError still stop the polling.
+func TolerateErrors() PollOption {
+	return func(config *pollConfig) {
+		config.tolerateErrors = true
+	}
+}
+
+// Poll checks condition using policy delays until it is done.
+// A condition returning false keeps polling, by default a condition error stops polling and is returned as is.
+// When the policy gives up or the context ends it returns ErrConditionNotMet, wrapping the last tolerated
+// error or the context error if any.
+func Poll(ctx context.Context, policy Policy, condition Condition, options ...PollOption) error {
+	config := pollConfig{}
+	for _, option := range options {
+		option(&config)
+	}
+
+	var fatal bool
+	_, err := newPolicyRetryer[struct{}](policy).Retry(ctx, handleRun(func(ctx context.Context) (struct{}, error) {
+		done, err := condition(ctx)
+		if err != nil {
+			var permanent *internal.PermanentError
+			if errors.As(err, &permanent) {
+				fatal = true
+				return struct{}{}, err
+			}
+			if config.tolerateErrors || ctx.Err() != nil {
+				// a condition failing because the context ended didn't fail by itself
+				return struct{}{}, err
+			}
+			fatal = true
+			return struct{}{}, internal.Permanent(err)
+		}
+		if !done {
+			return struct{}{}, errConditionFalse
+		}
+
+		return struct{}{}, nil
+	}))
+
+	switch {
+	case err == nil, fatal:
+		return err
+	case errors.Is(err, errConditionFalse):
+		return ErrConditionNotMet
+	default:
+		return fmt.Errorf("%w: %w", ErrConditionNotMet, err)
+	}
+}
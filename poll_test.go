@@ -0,0 +1,121 @@
+package again_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+)
+
+func TestPoll(t *testing.T) {
+	t.Run("condition is checked until it is met", func(t *testing.T) {
+		checked := 0
+		policy := again.ConstantDelay(time.Millisecond, time.Second)
+
+		err := again.Poll(context.Background(), policy, func(ctx context.Context) (bool, error) {
+			checked++
+			return checked == 3, nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 3, checked)
+	})
+
+	t.Run("condition not met when the policy gives up", func(t *testing.T) {
+		policy := again.ConstantDelay(time.Millisecond, 5*time.Millisecond)
+
+		err := again.Poll(context.Background(), policy, func(ctx context.Context) (bool, error) {
+			return false, nil
+		})
+
+		require.Equal(t, again.ErrConditionNotMet, err)
+	})
+
+	t.Run("condition not met when the context ends", func(t *testing.T) {
+		policy := again.ConstantDelay(time.Millisecond, time.Hour)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+
+		err := again.Poll(ctx, policy, func(ctx context.Context) (bool, error) {
+			return false, nil
+		})
+
+		require.ErrorIs(t, err, again.ErrConditionNotMet)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("condition not met when the context ends during the check", func(t *testing.T) {
+		policy := again.ConstantDelay(time.Millisecond, time.Hour)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+
+		err := again.Poll(ctx, policy, func(ctx context.Context) (bool, error) {
+			<-ctx.Done()
+			return false, fmt.Errorf("GET /health: %w", ctx.Err())
+		})
+
+		require.ErrorIs(t, err, again.ErrConditionNotMet)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("errors are fatal by default", func(t *testing.T) {
+		checked := 0
+		expectedErr := errors.New("whatever")
+		policy := again.ConstantDelay(time.Millisecond, time.Second)
+
+		err := again.Poll(context.Background(), policy, func(ctx context.Context) (bool, error) {
+			checked++
+			return false, expectedErr
+		})
+
+		require.Equal(t, expectedErr, err)
+		require.Equal(t, 1, checked)
+	})
+
+	t.Run("tolerated errors keep polling", func(t *testing.T) {
+		checked := 0
+		policy := again.ConstantDelay(time.Millisecond, time.Second)
+
+		err := again.Poll(context.Background(), policy, func(ctx context.Context) (bool, error) {
+			checked++
+			if checked < 3 {
+				return false, errors.New("connection refused")
+			}
+			return true, nil
+		}, again.TolerateErrors())
+		require.NoError(t, err)
+
+		require.Equal(t, 3, checked)
+	})
+
+	t.Run("last tolerated error is wrapped when the policy gives up", func(t *testing.T) {
+		expectedErr := errors.New("connection refused")
+		policy := again.ConstantDelay(time.Millisecond, 5*time.Millisecond)
+
+		err := again.Poll(context.Background(), policy, func(ctx context.Context) (bool, error) {
+			return false, expectedErr
+		}, again.TolerateErrors())
+
+		require.ErrorIs(t, err, again.ErrConditionNotMet)
+		require.ErrorIs(t, err, expectedErr)
+	})
+
+	t.Run("permanent errors stop tolerant polling", func(t *testing.T) {
+		checked := 0
+		expectedErr := errors.New("not found")
+		policy := again.ConstantDelay(time.Millisecond, time.Second)
+
+		err := again.Poll(context.Background(), policy, func(ctx context.Context) (bool, error) {
+			checked++
+			return false, again.NewPermanentError(expectedErr)
+		}, again.TolerateErrors())
+
+		require.Equal(t, expectedErr, err)
+		require.Equal(t, 1, checked)
+	})
+}
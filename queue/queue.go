@@ -0,0 +1,288 @@
+// Package queue runs fire-and-forget tasks in the background, retrying them with go-again policies
+// without holding a worker while a task waits for its next attempt.
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jdvr/go-again"
+	"github.com/jdvr/go-again/internal"
+)
+
+const defaultWorkers = 4
+
+var (
+	// ErrClosed is returned by Submit once Shutdown has been called.
+	ErrClosed = errors.New("queue: closed")
+	// ErrShutdown is the dead letter error of jobs still pending when Shutdown gives up waiting.
+	ErrShutdown = errors.New("queue: shutdown before job completion")
+)
+
+// Task is the work retried by the queue, returning an error wrapped with again.NewPermanentError stops retrying it.
+type Task func(ctx context.Context) error
+
+// Job is a task submitted with the policy used to retry it.
+type Job struct {
+	// ID identifies the job in dead letters
+	ID string
+	// Task runs on every attempt
+	Task Task
+	// Policy calculates delays between attempts
+	Policy again.Policy
+}
+
+// DeadLetter reports a job that won't be retried anymore.
+type DeadLetter struct {
+	Job      Job
+	Attempts int
+	Err      error
+}
+
+// Config Set values for the queue.
+type Config struct {
+	// Workers is the maximum number of tasks running at the same time, 4 by default
+	Workers int
+	// OnDeadLetter is called from a worker when a job is given up
+	OnDeadLetter func(letter DeadLetter)
+	// DeadLetters receives given up jobs, sends block the worker so it must be consumed
+	DeadLetters chan<- DeadLetter
+}
+
+// Queue runs jobs on a bounded worker pool and reschedules failed ones according to their policy.
+type Queue struct {
+	config Config
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	closed  bool
+	pending sync.WaitGroup
+	workers sync.WaitGroup
+
+	incoming chan *scheduledJob
+	work     chan *scheduledJob
+	stop     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+type scheduledJob struct {
+	job        Job
+	calculator internal.TicksCalculator
+	attempts   int
+	dueAt      time.Time
+}
+
+// New starts a queue with its workers.
+func New(config Config) *Queue {
+	if config.Workers <= 0 {
+		config.Workers = defaultWorkers
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		config:   config,
+		ctx:      ctx,
+		cancel:   cancel,
+		incoming: make(chan *scheduledJob),
+		work:     make(chan *scheduledJob),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	go q.schedule()
+	q.workers.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go q.worker()
+	}
+
+	return q
+}
+
+// Submit schedules the job to run as soon as a worker is available.
+func (q *Queue) Submit(job Job) error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return ErrClosed
+	}
+	q.pending.Add(1)
+	q.mu.Unlock()
+
+	q.enqueue(&scheduledJob{
+		job:        job,
+		calculator: job.Policy.NewTicksCalculator(nil),
+		dueAt:      time.Now(),
+	})
+
+	return nil
+}
+
+// Shutdown stops accepting jobs and waits until every pending job succeeds or is given up.
+// If ctx is done first, running tasks are cancelled, pending jobs are dead lettered with ErrShutdown
+// and the context error is returned.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		q.pending.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+		q.cancel()
+	}
+
+	q.stopOnce.Do(func() {
+		close(q.stop)
+	})
+	q.workers.Wait()
+	q.cancel()
+
+	return err
+}
+
+// enqueue hands the job to the scheduler, or dead letters it when the scheduler is gone.
+func (q *Queue) enqueue(job *scheduledJob) {
+	select {
+	case q.incoming <- job:
+	case <-q.stopped:
+		q.deadLetter(job, ErrShutdown)
+	}
+}
+
+// schedule owns the delayed jobs and hands due ones to idle workers.
+func (q *Queue) schedule() {
+	defer close(q.stopped)
+	defer close(q.work)
+
+	var delayed jobHeap
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		var (
+			next *scheduledJob
+			work chan *scheduledJob
+		)
+		if len(delayed) > 0 {
+			next = delayed[0]
+			if wait := time.Until(next.dueAt); wait > 0 {
+				resetTimer(timer, wait)
+			} else {
+				work = q.work
+			}
+		}
+
+		select {
+		case work <- next:
+			heap.Pop(&delayed)
+		case job := <-q.incoming:
+			heap.Push(&delayed, job)
+		case <-timer.C:
+		case <-q.stop:
+			for len(delayed) > 0 {
+				q.deadLetter(heap.Pop(&delayed).(*scheduledJob), ErrShutdown)
+			}
+			return
+		}
+	}
+}
+
+func (q *Queue) worker() {
+	defer q.workers.Done()
+
+	for job := range q.work {
+		q.run(job)
+	}
+}
+
+func (q *Queue) run(job *scheduledJob) {
+	err := job.job.Task(q.ctx)
+	job.attempts++
+	if err == nil {
+		q.pending.Done()
+		return
+	}
+
+	var permanent *internal.PermanentError
+	if errors.As(err, &permanent) {
+		q.deadLetter(job, permanent.Err)
+		return
+	}
+	if cerr := q.ctx.Err(); cerr != nil {
+		q.deadLetter(job, ErrShutdown)
+		return
+	}
+	if !job.job.Policy.Retryable(err) {
+		q.deadLetter(job, err)
+		return
+	}
+
+	next := job.calculator.Next()
+	if next.Stop {
+		q.deadLetter(job, err)
+		return
+	}
+
+	job.dueAt = time.Now().Add(next.Next)
+	q.enqueue(job)
+}
+
+func (q *Queue) deadLetter(job *scheduledJob, err error) {
+	defer q.pending.Done()
+
+	letter := DeadLetter{
+		Job:      job.job,
+		Attempts: job.attempts,
+		Err:      err,
+	}
+	if q.config.OnDeadLetter != nil {
+		q.config.OnDeadLetter(letter)
+	}
+	if q.config.DeadLetters != nil {
+		q.config.DeadLetters <- letter
+	}
+}
+
+func resetTimer(timer *time.Timer, d time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(d)
+}
+
+// jobHeap orders scheduled jobs by due time.
+type jobHeap []*scheduledJob
+
+func (h jobHeap) Len() int           { return len(h) }
+func (h jobHeap) Less(i, j int) bool { return h[i].dueAt.Before(h[j].dueAt) }
+func (h jobHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *jobHeap) Push(x any) {
+	*h = append(*h, x.(*scheduledJob))
+}
+
+func (h *jobHeap) Pop() any {
+	old := *h
+	n := len(old)
+	job := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+
+	return job
+}
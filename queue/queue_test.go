@@ -0,0 +1,225 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+	"github.com/jdvr/go-again/queue"
+)
+
+var fastPolicy = again.ConstantDelay(time.Millisecond, time.Second)
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("tasks are retried until they succeed", func(t *testing.T) {
+		t.Parallel()
+		q := queue.New(queue.Config{Workers: 2})
+		var runs atomic.Int32
+
+		require.NoError(t, q.Submit(queue.Job{
+			ID:     "a",
+			Policy: fastPolicy,
+			Task: func(ctx context.Context) error {
+				if runs.Add(1) < 3 {
+					return errors.New("not yet")
+				}
+				return nil
+			},
+		}))
+		require.NoError(t, q.Shutdown(context.Background()))
+
+		require.EqualValues(t, 3, runs.Load())
+	})
+
+	t.Run("waiting jobs don't hold a worker", func(t *testing.T) {
+		t.Parallel()
+		q := queue.New(queue.Config{Workers: 1})
+		slowRetryFailed := make(chan struct{})
+		fastDone := make(chan struct{})
+
+		require.NoError(t, q.Submit(queue.Job{
+			ID:     "slow",
+			Policy: again.ConstantDelay(time.Hour, time.Hour),
+			Task: func(ctx context.Context) error {
+				select {
+				case <-slowRetryFailed:
+					return nil
+				default:
+					close(slowRetryFailed)
+					return errors.New("retry in an hour")
+				}
+			},
+		}))
+		<-slowRetryFailed
+		require.NoError(t, q.Submit(queue.Job{
+			ID:     "fast",
+			Policy: fastPolicy,
+			Task: func(ctx context.Context) error {
+				close(fastDone)
+				return nil
+			},
+		}))
+
+		select {
+		case <-fastDone:
+		case <-time.After(time.Second):
+			t.Fatal("fast job didn't run while slow job was waiting")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, q.Shutdown(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("given up jobs are dead lettered", func(t *testing.T) {
+		t.Parallel()
+		deadLetters := make(chan queue.DeadLetter, 2)
+		q := queue.New(queue.Config{DeadLetters: deadLetters})
+		expectedErr := errors.New("gone")
+
+		require.NoError(t, q.Submit(queue.Job{
+			ID:     "exhausted",
+			Policy: again.ConstantDelay(time.Millisecond, 5*time.Millisecond),
+			Task: func(ctx context.Context) error {
+				return errors.New("unavailable")
+			},
+		}))
+		require.NoError(t, q.Submit(queue.Job{
+			ID:     "permanent",
+			Policy: fastPolicy,
+			Task: func(ctx context.Context) error {
+				return again.NewPermanentError(expectedErr)
+			},
+		}))
+		require.NoError(t, q.Shutdown(context.Background()))
+		close(deadLetters)
+
+		letters := map[string]queue.DeadLetter{}
+		for letter := range deadLetters {
+			letters[letter.Job.ID] = letter
+		}
+		require.Len(t, letters, 2)
+		require.EqualError(t, letters["exhausted"].Err, "unavailable")
+		require.Greater(t, letters["exhausted"].Attempts, 1)
+		require.Equal(t, expectedErr, letters["permanent"].Err)
+		require.Equal(t, 1, letters["permanent"].Attempts)
+	})
+
+	t.Run("spec policies limit attempts and retryable errors", func(t *testing.T) {
+		t.Parallel()
+		deadLetters := make(chan queue.DeadLetter, 2)
+		q := queue.New(queue.Config{DeadLetters: deadLetters})
+		policy, err := again.PolicySpec{
+			Strategy:        again.StrategyConstant,
+			Delay:           again.Duration(time.Millisecond),
+			Timeout:         again.Duration(time.Minute),
+			MaxAttempts:     3,
+			RetryableErrors: []string{"unavailable"},
+		}.Policy()
+		require.NoError(t, err)
+
+		require.NoError(t, q.Submit(queue.Job{
+			ID:     "limited",
+			Policy: policy,
+			Task: func(ctx context.Context) error {
+				return errors.New("unavailable")
+			},
+		}))
+		require.NoError(t, q.Submit(queue.Job{
+			ID:     "not retryable",
+			Policy: policy,
+			Task: func(ctx context.Context) error {
+				return errors.New("invalid")
+			},
+		}))
+		require.NoError(t, q.Shutdown(context.Background()))
+		close(deadLetters)
+
+		letters := map[string]queue.DeadLetter{}
+		for letter := range deadLetters {
+			letters[letter.Job.ID] = letter
+		}
+		require.Len(t, letters, 2)
+		require.Equal(t, 3, letters["limited"].Attempts)
+		require.Equal(t, 1, letters["not retryable"].Attempts)
+	})
+
+	t.Run("pending jobs are dead lettered when shutdown times out", func(t *testing.T) {
+		t.Parallel()
+		var (
+			mu      sync.Mutex
+			letters []queue.DeadLetter
+		)
+		q := queue.New(queue.Config{
+			OnDeadLetter: func(letter queue.DeadLetter) {
+				mu.Lock()
+				defer mu.Unlock()
+				letters = append(letters, letter)
+			},
+		})
+		started := make(chan struct{})
+
+		require.NoError(t, q.Submit(queue.Job{
+			ID:     "running",
+			Policy: fastPolicy,
+			Task: func(ctx context.Context) error {
+				close(started)
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}))
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, q.Shutdown(ctx), context.DeadlineExceeded)
+
+		require.Len(t, letters, 1)
+		require.ErrorIs(t, letters[0].Err, queue.ErrShutdown)
+	})
+
+	t.Run("workers bound concurrency", func(t *testing.T) {
+		t.Parallel()
+		q := queue.New(queue.Config{Workers: 2})
+		var running, maxRunning atomic.Int32
+
+		for i := 0; i < 10; i++ {
+			require.NoError(t, q.Submit(queue.Job{
+				Policy: fastPolicy,
+				Task: func(ctx context.Context) error {
+					current := running.Add(1)
+					defer running.Add(-1)
+					for {
+						seen := maxRunning.Load()
+						if current <= seen || maxRunning.CompareAndSwap(seen, current) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					return nil
+				},
+			}))
+		}
+		require.NoError(t, q.Shutdown(context.Background()))
+
+		require.LessOrEqual(t, maxRunning.Load(), int32(2))
+	})
+
+	t.Run("submit fails once shutdown", func(t *testing.T) {
+		t.Parallel()
+		q := queue.New(queue.Config{})
+		require.NoError(t, q.Shutdown(context.Background()))
+
+		err := q.Submit(queue.Job{Task: func(ctx context.Context) error { return nil }})
+
+		require.ErrorIs(t, err, queue.ErrClosed)
+	})
+}
@@ -0,0 +1,114 @@
+package again
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jdvr/go-again/internal"
+)
+
+// ErrRateLimited is returned when waiting for the rate limiter would outlast the context deadline.
+var ErrRateLimited = errors.New("again: rate limit wait exceeds context deadline")
+
+// RateLimiter is a token bucket limiting the rate of attempts of every retryer sharing it.
+// It is safe for concurrent use.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	stats  RateLimiterStats
+}
+
+// RateLimiterStats counts the attempts let through by a RateLimiter, its waits are not backoff sleeps.
+type RateLimiterStats struct {
+	// Acquired is the number of attempts let through
+	Acquired int64
+	// Waited is the number of attempts that had to wait for a token
+	Waited int64
+	// WaitTime is the total time attempts waited for a token
+	WaitTime time.Duration
+}
+
+// NewRateLimiter returns a full token bucket allowing perSecond attempts on average and burst at once.
+// It panics if perSecond or burst are not positive.
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	if perSecond <= 0 || burst <= 0 {
+		panic("rate and burst must be positive")
+	}
+
+	return &RateLimiter{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// WithRateLimiter makes every attempt wait for a token from limiter before running.
+// The wait is bounded by the context, ErrRateLimited is returned right away when it would outlast its deadline.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(config *internal.RetryerConfig) {
+		config.Limiter = limiter
+	}
+}
+
+// Wait takes a token, waiting until one is available or ctx ends.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		l.tokens++
+		l.mu.Unlock()
+		return ErrRateLimited
+	}
+	if wait == 0 {
+		l.stats.Acquired++
+		l.mu.Unlock()
+		return nil
+	}
+	l.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats.Acquired++
+	l.stats.Waited++
+	l.stats.WaitTime += wait
+
+	return nil
+}
+
+// Stats returns the limiter counters.
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stats
+}
+
+// refill adds the tokens earned since the last refill, it must be called holding the lock.
+func (l *RateLimiter) refill(now time.Time) {
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+}
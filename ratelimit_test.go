@@ -0,0 +1,72 @@
+package again_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+)
+
+func TestWithRateLimiter(t *testing.T) {
+	t.Run("limiter is shared across retryers", func(t *testing.T) {
+		limiter := again.NewRateLimiter(200, 1)
+		policy := again.ConstantDelay(time.Microsecond, time.Second,
+			again.WithRateLimiter(limiter), again.WithMaxAttempts(5))
+
+		startAt := time.Now()
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = again.Do(context.Background(), policy, func(ctx context.Context) error {
+					return errors.New("failing")
+				})
+			}()
+		}
+		wg.Wait()
+
+		// the first attempt uses the burst, the nine others wait 5ms each
+		require.GreaterOrEqual(t, time.Since(startAt), 40*time.Millisecond)
+		stats := limiter.Stats()
+		require.EqualValues(t, 10, stats.Acquired)
+		require.EqualValues(t, 9, stats.Waited)
+		require.Greater(t, stats.WaitTime, 40*time.Millisecond)
+	})
+
+	t.Run("wait beyond the context deadline fails right away", func(t *testing.T) {
+		limiter := again.NewRateLimiter(1, 1)
+		policy := again.ConstantDelay(time.Millisecond, time.Second, again.WithRateLimiter(limiter))
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		called := 0
+		startAt := time.Now()
+		err := again.Do(ctx, policy, func(ctx context.Context) error {
+			called++
+			return errors.New("failing")
+		})
+
+		require.ErrorIs(t, err, again.ErrRateLimited)
+		require.Equal(t, 1, called)
+		require.Less(t, time.Since(startAt), 50*time.Millisecond)
+	})
+
+	t.Run("cancelled wait gives the token back", func(t *testing.T) {
+		limiter := again.NewRateLimiter(10, 1)
+		require.NoError(t, limiter.Wait(context.Background()))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.ErrorIs(t, limiter.Wait(ctx), context.Canceled)
+
+		startAt := time.Now()
+		require.NoError(t, limiter.Wait(context.Background()))
+		require.Less(t, time.Since(startAt), 150*time.Millisecond)
+	})
+}
@@ -0,0 +1,296 @@
+// Package redial provides a net.Conn that dials through a go-again policy and transparently redials
+// when the connection breaks.
+package redial
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jdvr/go-again"
+)
+
+// DialFunc opens a new connection, returning an error wrapped with again.NewPermanentError stops redialing.
+type DialFunc func(ctx context.Context) (net.Conn, error)
+
+// Mode defines what happens to a read or write interrupted by a broken connection.
+type Mode int
+
+const (
+	// ReturnError returns the error to the caller, the next read or write redials.
+	ReturnError Mode = iota
+	// Replay redials and runs the interrupted operation again on the new connection, a write sends the whole
+	// buffer again so the peer may receive part of it twice. A peer closing the connection ends the stream:
+	// the read returns io.EOF and the next operation redials.
+	Replay
+)
+
+// State is the connection lifecycle reported to Config.OnStateChange.
+type State int
+
+const (
+	Connecting State = iota
+	Connected
+	Disconnected
+	Closed
+)
+
+func (s State) String() string {
+	switch s {
+	case Connecting:
+		return "connecting"
+	case Connected:
+		return "connected"
+	case Disconnected:
+		return "disconnected"
+	case Closed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// Config Set values for the reconnecting connection.
+type Config struct {
+	// Dial opens every underlying connection
+	Dial DialFunc
+	// Policy retries dialing, every (re)dial gets a fresh policy budget
+	Policy again.Policy
+	// Mode defines how operations interrupted by a broken connection are handled
+	Mode Mode
+	// OnStateChange is called on every state transition with the error causing it if any,
+	// it runs synchronously so it must not use the connection
+	OnStateChange func(state State, err error)
+}
+
+// Conn is a net.Conn redialing when the underlying connection breaks, it is safe for concurrent use
+// like any net.Conn.
+type Conn struct {
+	config Config
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu         sync.Mutex
+	conn       net.Conn
+	closed     bool
+	localAddr  net.Addr
+	remoteAddr net.Addr
+	readDL     time.Time
+	writeDL    time.Time
+}
+
+var _ net.Conn = &Conn{}
+
+// Dial opens the first connection using the policy, ctx bounds this first dial only.
+func Dial(ctx context.Context, config Config) (*Conn, error) {
+	if config.Dial == nil {
+		panic("redial: Dial: nil DialFunc")
+	}
+	base, cancel := context.WithCancel(context.Background())
+	c := &Conn{
+		config: config,
+		ctx:    base,
+		cancel: cancel,
+	}
+
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.connectLocked(); err != nil {
+		cancel()
+		if cerr := ctx.Err(); cerr != nil {
+			return nil, cerr
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *Conn) Read(p []byte) (int, error) {
+	for {
+		conn, err := c.current()
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := conn.Read(p)
+		if err == nil || !c.broken(conn, err) {
+			return n, err
+		}
+		if c.config.Mode != Replay || errors.Is(err, io.EOF) {
+			return n, err
+		}
+		if n > 0 {
+			// the next read continues on a new connection
+			return n, nil
+		}
+	}
+}
+
+func (c *Conn) Write(p []byte) (int, error) {
+	for {
+		conn, err := c.current()
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := conn.Write(p)
+		if err == nil || !c.broken(conn, err) {
+			return n, err
+		}
+		if c.config.Mode != Replay {
+			return n, err
+		}
+	}
+}
+
+// Close closes the underlying connection and stops redialing.
+func (c *Conn) Close() error {
+	c.cancel()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return net.ErrClosed
+	}
+	c.closed = true
+
+	var err error
+	if c.conn != nil {
+		err = c.conn.Close()
+		c.conn = nil
+	}
+	c.notify(Closed, nil)
+
+	return err
+}
+
+// LocalAddr returns the local address of the current or last connection.
+func (c *Conn) LocalAddr() net.Addr {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.localAddr
+}
+
+// RemoteAddr returns the remote address of the current or last connection.
+func (c *Conn) RemoteAddr() net.Addr {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.remoteAddr
+}
+
+// SetDeadline sets read and write deadlines, they are kept for connections dialed later.
+func (c *Conn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readDL, c.writeDL = t, t
+	if c.conn != nil {
+		return c.conn.SetDeadline(t)
+	}
+
+	return nil
+}
+
+// SetReadDeadline sets the read deadline, it is kept for connections dialed later.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readDL = t
+	if c.conn != nil {
+		return c.conn.SetReadDeadline(t)
+	}
+
+	return nil
+}
+
+// SetWriteDeadline sets the write deadline, it is kept for connections dialed later.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.writeDL = t
+	if c.conn != nil {
+		return c.conn.SetWriteDeadline(t)
+	}
+
+	return nil
+}
+
+// current returns the connection, redialing if the previous one broke.
+func (c *Conn) current() (net.Conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, net.ErrClosed
+	}
+	if c.conn != nil {
+		return c.conn, nil
+	}
+
+	return c.connectLocked()
+}
+
+func (c *Conn) connectLocked() (net.Conn, error) {
+	c.notify(Connecting, nil)
+	conn, err := again.Get[net.Conn](c.ctx, c.config.Policy, func(ctx context.Context) (net.Conn, error) {
+		return c.config.Dial(ctx)
+	})
+	if err != nil {
+		c.notify(Disconnected, err)
+		return nil, err
+	}
+
+	if !c.readDL.IsZero() {
+		_ = conn.SetReadDeadline(c.readDL)
+	}
+	if !c.writeDL.IsZero() {
+		_ = conn.SetWriteDeadline(c.writeDL)
+	}
+	c.conn = conn
+	c.localAddr = conn.LocalAddr()
+	c.remoteAddr = conn.RemoteAddr()
+	c.notify(Connected, nil)
+
+	return conn, nil
+}
+
+// broken reports whether err means conn can't be used anymore, in that case conn is dropped
+// unless another operation already replaced it.
+func (c *Conn) broken(conn net.Conn, err error) bool {
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+	if c.conn == conn {
+		_ = conn.Close()
+		c.conn = nil
+		c.notify(Disconnected, err)
+	}
+
+	return true
+}
+
+func (c *Conn) notify(state State, err error) {
+	if c.config.OnStateChange != nil {
+		c.config.OnStateChange(state, err)
+	}
+}
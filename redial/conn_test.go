@@ -0,0 +1,284 @@
+package redial_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+	"github.com/jdvr/go-again/redial"
+)
+
+var testPolicy = again.ConstantDelay(time.Millisecond, time.Second)
+
+// lineServer answers every line with the same line, after answering closeAfter lines it closes the connection.
+type lineServer struct {
+	listener   net.Listener
+	closeAfter int
+
+	mu       sync.Mutex
+	accepted int
+}
+
+func newLineServer(t *testing.T, closeAfter int) *lineServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &lineServer{listener: listener, closeAfter: closeAfter}
+	t.Cleanup(func() {
+		listener.Close()
+	})
+	go server.serve()
+
+	return server
+}
+
+func (s *lineServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.accepted++
+		s.mu.Unlock()
+
+		go func() {
+			defer conn.Close()
+			reader := bufio.NewReader(conn)
+			for answered := 0; s.closeAfter == 0 || answered < s.closeAfter; answered++ {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if _, err := conn.Write([]byte(line)); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+func (s *lineServer) Accepted() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.accepted
+}
+
+func (s *lineServer) dial(ctx context.Context) (net.Conn, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", s.listener.Addr().String())
+}
+
+func roundTrip(t *testing.T, conn net.Conn, line string) (string, error) {
+	t.Helper()
+	if _, err := conn.Write([]byte(line + "\n")); err != nil {
+		return "", err
+	}
+	answer := make([]byte, len(line)+1)
+	_, err := io.ReadFull(conn, answer)
+
+	return string(answer), err
+}
+
+// resetConn is a connection reset by the peer.
+type resetConn struct {
+	net.Conn
+}
+
+func (resetConn) Read([]byte) (int, error) {
+	return 0, syscall.ECONNRESET
+}
+
+func TestConn(t *testing.T) {
+	t.Run("replay mode redials transparently", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		go func() {
+			// every connection gets a greeting and is closed
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				_, _ = conn.Write([]byte("hello\n"))
+				conn.Close()
+			}
+		}()
+		var (
+			mu     sync.Mutex
+			states []redial.State
+			dials  int
+		)
+		conn, err := redial.Dial(context.Background(), redial.Config{
+			Dial: func(ctx context.Context) (net.Conn, error) {
+				dials++
+				if dials == 1 {
+					client, server := net.Pipe()
+					server.Close()
+					return resetConn{Conn: client}, nil
+				}
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "tcp", listener.Addr().String())
+			},
+			Policy: testPolicy,
+			Mode:   redial.Replay,
+			OnStateChange: func(state redial.State, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				states = append(states, state)
+			},
+		})
+		require.NoError(t, err)
+		defer conn.Close()
+
+		greeting := make([]byte, len("hello\n"))
+		_, err = io.ReadFull(conn, greeting)
+		require.NoError(t, err)
+
+		require.Equal(t, "hello\n", string(greeting))
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, []redial.State{
+			redial.Connecting, redial.Connected,
+			redial.Disconnected,
+			redial.Connecting, redial.Connected,
+		}, states)
+	})
+
+	t.Run("replay mode returns the end of stream", func(t *testing.T) {
+		server := newLineServer(t, 1)
+		conn, err := redial.Dial(context.Background(), redial.Config{
+			Dial:   server.dial,
+			Policy: testPolicy,
+			Mode:   redial.Replay,
+		})
+		require.NoError(t, err)
+		defer conn.Close()
+
+		answer, err := roundTrip(t, conn, "first")
+		require.NoError(t, err)
+		require.Equal(t, "first\n", answer)
+
+		_, err = conn.Read(make([]byte, 1))
+		require.ErrorIs(t, err, io.EOF)
+		require.Equal(t, 1, server.Accepted())
+
+		answer, err = roundTrip(t, conn, "second")
+		require.NoError(t, err)
+		require.Equal(t, "second\n", answer)
+		require.Equal(t, 2, server.Accepted())
+	})
+
+	t.Run("return error mode redials on next operation", func(t *testing.T) {
+		server := newLineServer(t, 1)
+		var states []redial.State
+		conn, err := redial.Dial(context.Background(), redial.Config{
+			Dial:   server.dial,
+			Policy: testPolicy,
+			OnStateChange: func(state redial.State, err error) {
+				states = append(states, state)
+			},
+		})
+		require.NoError(t, err)
+
+		answer, err := roundTrip(t, conn, "first")
+		require.NoError(t, err)
+		require.Equal(t, "first\n", answer)
+
+		_, err = conn.Read(make([]byte, 1))
+		require.ErrorIs(t, err, io.EOF)
+
+		answer, err = roundTrip(t, conn, "second")
+		require.NoError(t, err)
+		require.Equal(t, "second\n", answer)
+		require.Equal(t, 2, server.Accepted())
+
+		require.NoError(t, conn.Close())
+		require.Equal(t, []redial.State{
+			redial.Connecting, redial.Connected,
+			redial.Disconnected,
+			redial.Connecting, redial.Connected,
+			redial.Closed,
+		}, states)
+	})
+
+	t.Run("dial is retried using the policy", func(t *testing.T) {
+		server := newLineServer(t, 0)
+		attempts := 0
+		conn, err := redial.Dial(context.Background(), redial.Config{
+			Policy: testPolicy,
+			Dial: func(ctx context.Context) (net.Conn, error) {
+				attempts++
+				if attempts < 3 {
+					return nil, errors.New("connection refused")
+				}
+				return server.dial(ctx)
+			},
+		})
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.Equal(t, 3, attempts)
+		require.Equal(t, server.listener.Addr().String(), conn.RemoteAddr().String())
+	})
+
+	t.Run("dial fails when the policy gives up", func(t *testing.T) {
+		expectedErr := errors.New("connection refused")
+		_, err := redial.Dial(context.Background(), redial.Config{
+			Policy: again.ConstantDelay(time.Millisecond, 5*time.Millisecond),
+			Dial: func(ctx context.Context) (net.Conn, error) {
+				return nil, expectedErr
+			},
+		})
+
+		require.Equal(t, expectedErr, err)
+	})
+
+	t.Run("closed connection is not redialed", func(t *testing.T) {
+		server := newLineServer(t, 0)
+		conn, err := redial.Dial(context.Background(), redial.Config{
+			Dial:   server.dial,
+			Policy: testPolicy,
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, conn.Close())
+
+		_, err = conn.Write([]byte("line\n"))
+		require.ErrorIs(t, err, net.ErrClosed)
+		require.ErrorIs(t, conn.Close(), net.ErrClosed)
+		require.Equal(t, 1, server.Accepted())
+	})
+
+	t.Run("deadlines don't break the connection", func(t *testing.T) {
+		server := newLineServer(t, 0)
+		conn, err := redial.Dial(context.Background(), redial.Config{
+			Dial:   server.dial,
+			Policy: testPolicy,
+			Mode:   redial.Replay,
+		})
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Millisecond)))
+		_, err = conn.Read(make([]byte, 1))
+		require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+		require.NoError(t, conn.SetReadDeadline(time.Time{}))
+		answer, err := roundTrip(t, conn, "line")
+		require.NoError(t, err)
+		require.Equal(t, "line\n", answer)
+		require.Equal(t, 1, server.Accepted())
+	})
+}
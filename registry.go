@@ -0,0 +1,267 @@
+package again
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultWatchInterval = 5 * time.Second
+
+// ErrPolicyNotFound is returned when looking up a policy name that was never registered.
+var ErrPolicyNotFound = errors.New("again: policy not found")
+
+// DefaultRegistry is the registry used by RegisterPolicy and LookupPolicy.
+var DefaultRegistry = NewRegistry()
+
+// PolicyChange describes a policy replaced in a registry.
+type PolicyChange struct {
+	// Name of the policy
+	Name string
+	// Previous is the spec of the replaced policy, nil when there was none or it was registered from code
+	Previous *PolicySpec
+	// Current is the spec of the new policy, nil when it was registered from code
+	Current *PolicySpec
+}
+
+// Registry holds policies by name, it is safe for concurrent use. Policies can be replaced at any time:
+// calls that already started keep the policy they looked up, new lookups get the replacement.
+type Registry struct {
+	mu        sync.RWMutex
+	policies  map[string]registryEntry
+	listeners map[int]func(change PolicyChange)
+	nextID    int
+}
+
+type registryEntry struct {
+	policy Policy
+	spec   *PolicySpec
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		policies:  map[string]registryEntry{},
+		listeners: map[int]func(change PolicyChange){},
+	}
+}
+
+// Register adds the policy under name, replacing any policy with the same name.
+func (r *Registry) Register(name string, policy Policy) {
+	r.swap(map[string]registryEntry{name: {policy: policy}})
+}
+
+// RegisterSpec builds the spec and registers it under name, replacing any policy with the same name.
+func (r *Registry) RegisterSpec(name string, spec PolicySpec) error {
+	return r.LoadSpecs(map[string]PolicySpec{name: spec}, "")
+}
+
+// Lookup returns the policy registered under name or ErrPolicyNotFound.
+func (r *Registry) Lookup(name string) (Policy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.policies[name]
+	if !ok {
+		return Policy{}, fmt.Errorf("%w: %q", ErrPolicyNotFound, name)
+	}
+
+	return entry.policy, nil
+}
+
+// Live returns a policy looking up name on every call, so a long-lived value follows the replacements
+// of the registered policy. fallback is used while name is not registered.
+func (r *Registry) Live(name string, fallback Policy) Policy {
+	return Policy{resolve: func() Policy {
+		policy, err := r.Lookup(name)
+		if err != nil {
+			return fallback
+		}
+		return policy
+	}}
+}
+
+// OnChange calls listener after every policy replacement, until cancel is called.
+// Listeners are called synchronously by the goroutine replacing the policies.
+func (r *Registry) OnChange(listener func(change PolicyChange)) (cancel func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextID
+	r.nextID++
+	r.listeners[id] = listener
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.listeners, id)
+	}
+}
+
+// LoadSpecs builds and registers every spec by name. When envPrefix is not empty each spec is first overridden
+// by the environment variables starting with envPrefix, an underscore and the upper-cased name where any
+// character other than letters and digits becomes an underscore, e.g. AGAIN_PAYMENTS_API_TIMEOUT.
+// Nothing is registered if any spec is invalid, and specs equal to the registered ones are left untouched.
+func (r *Registry) LoadSpecs(specs map[string]PolicySpec, envPrefix string) error {
+	entries := make(map[string]registryEntry, len(specs))
+	for name, spec := range specs {
+		if envPrefix != "" {
+			var err error
+			if spec, err = spec.WithEnv(envPrefix + "_" + envName(name)); err != nil {
+				return err
+			}
+		}
+		policy, err := spec.Policy()
+		if err != nil {
+			return fmt.Errorf("policy %q: %w", name, err)
+		}
+		spec := spec
+		entries[name] = registryEntry{policy: policy, spec: &spec}
+	}
+
+	r.swap(entries)
+
+	return nil
+}
+
+// LoadJSON registers the policies of a JSON object mapping names to specs, see LoadSpecs.
+func (r *Registry) LoadJSON(data []byte, envPrefix string) error {
+	var specs map[string]PolicySpec
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return fmt.Errorf("again: decoding policies: %w", err)
+	}
+
+	return r.LoadSpecs(specs, envPrefix)
+}
+
+// LoadYAML registers the policies of a YAML mapping from names to specs, see LoadSpecs.
+func (r *Registry) LoadYAML(data []byte, envPrefix string) error {
+	var specs map[string]PolicySpec
+	if err := yaml.Unmarshal(data, &specs); err != nil {
+		return fmt.Errorf("again: decoding policies: %w", err)
+	}
+
+	return r.LoadSpecs(specs, envPrefix)
+}
+
+// WatchConfig Set values for Registry.WatchFile.
+type WatchConfig struct {
+	// Interval between checks of the file, five seconds by default
+	Interval time.Duration
+	// EnvPrefix enables environment overrides, see LoadSpecs
+	EnvPrefix string
+	// OnError is called when a changed file can't be read or loaded, the registered policies are kept
+	OnError func(err error)
+}
+
+// WatchFile loads the policies in path, JSON when its extension is .json and YAML otherwise, and reloads
+// them in background every time the file content changes until ctx is done.
+// It returns the error of the first load, in which case the file is not watched.
+// Policies removed from the file stay registered.
+func (r *Registry) WatchFile(ctx context.Context, path string, config WatchConfig) error {
+	if config.Interval <= 0 {
+		config.Interval = defaultWatchInterval
+	}
+	load := r.LoadYAML
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		load = r.LoadJSON
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := load(data, config.EnvPrefix); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+
+		loaded := data
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			data, err := os.ReadFile(path)
+			if err == nil {
+				if bytes.Equal(data, loaded) {
+					continue
+				}
+				err = load(data, config.EnvPrefix)
+			}
+			if err != nil {
+				if config.OnError != nil {
+					config.OnError(err)
+				}
+				continue
+			}
+			loaded = data
+		}
+	}()
+
+	return nil
+}
+
+// swap replaces the entries at once and notifies the listeners of the changed ones.
+func (r *Registry) swap(entries map[string]registryEntry) {
+	var changes []PolicyChange
+
+	r.mu.Lock()
+	for name, entry := range entries {
+		previous, ok := r.policies[name]
+		if ok && previous.spec != nil && entry.spec != nil && reflect.DeepEqual(*previous.spec, *entry.spec) {
+			continue
+		}
+		r.policies[name] = entry
+		changes = append(changes, PolicyChange{Name: name, Previous: previous.spec, Current: entry.spec})
+	}
+	listeners := make([]func(change PolicyChange), 0, len(r.listeners))
+	for _, listener := range r.listeners {
+		listeners = append(listeners, listener)
+	}
+	r.mu.Unlock()
+
+	for _, change := range changes {
+		for _, listener := range listeners {
+			listener(change)
+		}
+	}
+}
+
+// RegisterPolicy adds the policy to DefaultRegistry under name.
+func RegisterPolicy(name string, policy Policy) {
+	DefaultRegistry.Register(name, policy)
+}
+
+// LookupPolicy returns the policy registered in DefaultRegistry under name or ErrPolicyNotFound.
+func LookupPolicy(name string) (Policy, error) {
+	return DefaultRegistry.Lookup(name)
+}
+
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,189 @@
+package again_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("lookup unknown policy", func(t *testing.T) {
+		_, err := again.NewRegistry().Lookup("payments-api")
+
+		require.ErrorIs(t, err, again.ErrPolicyNotFound)
+	})
+
+	t.Run("load yaml policies with environment overrides", func(t *testing.T) {
+		t.Setenv("AGAIN_PAYMENTS_API_MAX_ATTEMPTS", "2")
+		registry := again.NewRegistry()
+
+		err := registry.LoadYAML([]byte(`
+payments-api:
+  strategy: constant
+  delay: 1ms
+  timeout: 1s
+  maxAttempts: 5
+`), "AGAIN")
+		require.NoError(t, err)
+
+		policy, err := registry.Lookup("payments-api")
+		require.NoError(t, err)
+		called := 0
+		_ = again.Do(context.Background(), policy, func(ctx context.Context) error {
+			called++
+			return errors.New("failing")
+		})
+		require.Equal(t, 2, called)
+	})
+
+	t.Run("load json policies", func(t *testing.T) {
+		registry := again.NewRegistry()
+
+		err := registry.LoadJSON([]byte(`{
+			"search": {"initialInterval": "1ms", "timeout": "1s", "disableJitter": true},
+			"ledger": {"strategy": "linear", "initialInterval": "1ms", "increment": "1ms", "timeout": "1s"}
+		}`), "")
+		require.NoError(t, err)
+
+		for _, name := range []string{"search", "ledger"} {
+			_, err := registry.Lookup(name)
+			require.NoError(t, err, name)
+		}
+	})
+
+	t.Run("invalid spec registers nothing", func(t *testing.T) {
+		registry := again.NewRegistry()
+
+		err := registry.LoadJSON([]byte(`{
+			"search": {"timeout": "1s"},
+			"broken": {"strategy": "constant"}
+		}`), "")
+		require.ErrorContains(t, err, `policy "broken"`)
+
+		_, err = registry.Lookup("search")
+		require.ErrorIs(t, err, again.ErrPolicyNotFound)
+	})
+
+	t.Run("default registry", func(t *testing.T) {
+		again.RegisterPolicy("registry-test", again.ConstantDelay(time.Millisecond, time.Second))
+
+		_, err := again.LookupPolicy("registry-test")
+		require.NoError(t, err)
+	})
+}
+
+func TestRegistry_HotReload(t *testing.T) {
+	countAttempts := func(policy again.Policy, whileRunning func()) int {
+		called := 0
+		_ = again.Do(context.Background(), policy, func(ctx context.Context) error {
+			called++
+			if called == 1 && whileRunning != nil {
+				whileRunning()
+			}
+			return errors.New("failing")
+		})
+		return called
+	}
+	spec := func(attempts int) again.PolicySpec {
+		return again.PolicySpec{
+			Strategy:    again.StrategyConstant,
+			Delay:       again.Duration(time.Millisecond),
+			Timeout:     again.Duration(time.Second),
+			MaxAttempts: attempts,
+		}
+	}
+
+	t.Run("in-flight calls keep their policy while live policies follow the replacement", func(t *testing.T) {
+		registry := again.NewRegistry()
+		require.NoError(t, registry.RegisterSpec("payments-api", spec(3)))
+		live := registry.Live("payments-api", again.Policy{})
+
+		called := countAttempts(live, func() {
+			require.NoError(t, registry.RegisterSpec("payments-api", spec(1)))
+		})
+		require.Equal(t, 3, called)
+
+		require.Equal(t, 1, countAttempts(live, nil))
+	})
+
+	t.Run("live policy uses fallback until registered", func(t *testing.T) {
+		registry := again.NewRegistry()
+		live := registry.Live("payments-api", again.ConstantDelay(time.Millisecond, time.Second, again.WithMaxAttempts(2)))
+
+		require.Equal(t, 2, countAttempts(live, nil))
+	})
+
+	t.Run("change notifications", func(t *testing.T) {
+		registry := again.NewRegistry()
+		var changes []again.PolicyChange
+		cancel := registry.OnChange(func(change again.PolicyChange) {
+			changes = append(changes, change)
+		})
+
+		require.NoError(t, registry.RegisterSpec("payments-api", spec(3)))
+		require.NoError(t, registry.RegisterSpec("payments-api", spec(3)))
+		require.NoError(t, registry.RegisterSpec("payments-api", spec(1)))
+		cancel()
+		require.NoError(t, registry.RegisterSpec("payments-api", spec(2)))
+
+		require.Len(t, changes, 2)
+		require.Nil(t, changes[0].Previous)
+		require.Equal(t, 3, changes[0].Current.MaxAttempts)
+		require.Equal(t, 3, changes[1].Previous.MaxAttempts)
+		require.Equal(t, 1, changes[1].Current.MaxAttempts)
+	})
+
+	t.Run("watched file is reloaded on change", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "policies.yaml")
+		write := func(content string) {
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		}
+		write("payments-api: {strategy: constant, delay: 1ms, timeout: 1s, maxAttempts: 3}")
+
+		registry := again.NewRegistry()
+		changed := make(chan again.PolicyChange, 1)
+		registry.OnChange(func(change again.PolicyChange) {
+			changed <- change
+		})
+		var (
+			mu       sync.Mutex
+			watchErr error
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		err := registry.WatchFile(ctx, path, again.WatchConfig{
+			Interval: time.Millisecond,
+			OnError: func(err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				watchErr = err
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, (<-changed).Current.MaxAttempts)
+
+		write("payments-api: {strategy: unknown}")
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return watchErr != nil
+		}, time.Second, time.Millisecond)
+
+		write("payments-api: {strategy: constant, delay: 1ms, timeout: 1s, maxAttempts: 1}")
+		select {
+		case change := <-changed:
+			require.Equal(t, 1, change.Current.MaxAttempts)
+		case <-time.After(time.Second):
+			t.Fatal("policy not reloaded")
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package resume
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jdvr/go-again"
+)
+
+// ErrRangeNotSupported is returned when the server ignores the Range header while resuming.
+var ErrRangeNotSupported = errors.New("resume: server doesn't support range requests")
+
+// ErrUnexpectedRange is returned when the server answers a range request with another range.
+var ErrUnexpectedRange = errors.New("resume: unexpected content range")
+
+// HTTPRange returns an OpenFunc downloading url with a GET request, resuming with a Range header.
+// Server errors are retried while client errors and servers ignoring ranges stop the download.
+// A range starting at the end of the content, when the connection dropped right before the end of the
+// response, is read as an empty body. http.DefaultClient is used when client is nil.
+func HTTPRange(client *http.Client, url string) OpenFunc {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	// length of the content announced by previous responses, -1 while unknown
+	var length atomic.Int64
+	length.Store(-1)
+
+	return func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, again.NewPermanentError(err)
+		}
+		if offset > 0 {
+			request.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+		}
+
+		response, err := client.Do(request)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case offset == 0 && response.StatusCode == http.StatusOK:
+			length.Store(response.ContentLength)
+			return response.Body, nil
+		case offset > 0 && response.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+			total, ok := contentRangeLength(response.Header.Get("Content-Range"))
+			if ok && total == offset || length.Load() == offset {
+				response.Body.Close()
+				return http.NoBody, nil
+			}
+		case offset > 0 && response.StatusCode == http.StatusPartialContent:
+			if total, ok := contentRangeLength(response.Header.Get("Content-Range")); ok {
+				length.Store(total)
+			}
+			start, err := contentRangeStart(response.Header.Get("Content-Range"))
+			if err == nil && start != offset {
+				err = fmt.Errorf("%w: got range starting at %d, want %d", ErrUnexpectedRange, start, offset)
+			}
+			if err != nil {
+				response.Body.Close()
+				return nil, again.NewPermanentError(err)
+			}
+			return response.Body, nil
+		case offset > 0 && response.StatusCode == http.StatusOK:
+			response.Body.Close()
+			return nil, again.NewPermanentError(ErrRangeNotSupported)
+		}
+
+		response.Body.Close()
+		err = fmt.Errorf("resume: GET %s: unexpected status %s", url, response.Status)
+		if response.StatusCode >= http.StatusInternalServerError || response.StatusCode == http.StatusTooManyRequests {
+			return nil, err
+		}
+
+		return nil, again.NewPermanentError(err)
+	}
+}
+
+// contentRangeStart returns the first byte position of a "bytes first-last/length" Content-Range header.
+func contentRangeStart(header string) (int64, error) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnexpectedRange, header)
+	}
+	first, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnexpectedRange, header)
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrUnexpectedRange, header)
+	}
+
+	return start, nil
+}
+
+// contentRangeLength returns the complete length of a "bytes first-last/length" or "bytes */length"
+// Content-Range header, ok is false when it is unknown.
+func contentRangeLength(header string) (length int64, ok bool) {
+	_, total, found := strings.Cut(header, "/")
+	if !found {
+		return 0, false
+	}
+	length, err := strconv.ParseInt(total, 10, 64)
+
+	return length, err == nil
+}
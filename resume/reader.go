@@ -0,0 +1,145 @@
+// Package resume continues interrupted streams from the last read offset using go-again policies.
+package resume
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"hash"
+	"io"
+
+	"github.com/jdvr/go-again"
+)
+
+// ErrChecksumMismatch is returned at the end of the stream when the content doesn't match Config.Checksum.
+var ErrChecksumMismatch = errors.New("resume: checksum mismatch")
+
+var errClosed = errors.New("resume: read on closed reader")
+
+// OpenFunc opens the source so the first byte read is the one at offset.
+// Returning an error wrapped with again.NewPermanentError stops retrying.
+type OpenFunc func(ctx context.Context, offset int64) (io.ReadCloser, error)
+
+// Config Set values for the resumable reader.
+type Config struct {
+	// Policy retries opening the source and reading from it, every Read call gets a fresh policy budget
+	Policy again.Policy
+	// Hash receives every byte read, it is compared to Checksum once the stream ends
+	Hash hash.Hash
+	// Checksum is the expected Hash sum, the content is not verified when it is empty
+	Checksum []byte
+}
+
+// Reader reads from a source reopening it from the current offset when a read fails.
+type Reader struct {
+	ctx    context.Context
+	open   OpenFunc
+	config Config
+
+	body   io.ReadCloser
+	offset int64
+	eof    bool
+	err    error
+}
+
+var _ io.ReadCloser = &Reader{}
+
+// NewReader returns a reader opening the source lazily on the first Read.
+func NewReader(ctx context.Context, open OpenFunc, config Config) *Reader {
+	return &Reader{
+		ctx:    ctx,
+		open:   open,
+		config: config,
+	}
+}
+
+// Offset returns the number of bytes read so far.
+func (r *Reader) Offset() int64 {
+	return r.offset
+}
+
+// Read reads from the source, on a failed read the source is closed and reopened from the current offset
+// until some bytes are read or the policy gives up.
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	n, err := again.Get[int](r.ctx, r.config.Policy, func(ctx context.Context) (int, error) {
+		if r.body == nil {
+			body, err := r.open(ctx, r.offset)
+			if err != nil {
+				return 0, err
+			}
+			r.body = body
+		}
+
+		n, err := r.body.Read(p)
+		switch {
+		case err == nil:
+			return n, nil
+		case errors.Is(err, io.EOF):
+			r.eof = true
+			return n, nil
+		case n > 0:
+			// keep the bytes read and reopen on next Read
+			r.closeBody()
+			return n, nil
+		default:
+			r.closeBody()
+			return 0, err
+		}
+	})
+	if err != nil {
+		r.err = err
+		return 0, err
+	}
+
+	r.offset += int64(n)
+	if r.config.Hash != nil {
+		r.config.Hash.Write(p[:n])
+	}
+	if r.eof {
+		r.err = r.verify()
+		if n == 0 {
+			return 0, r.err
+		}
+	}
+
+	return n, nil
+}
+
+// Close closes the current source if any.
+func (r *Reader) Close() error {
+	if r.err == nil {
+		r.err = errClosed
+	}
+	if r.body == nil {
+		return nil
+	}
+	body := r.body
+	r.body = nil
+
+	return body.Close()
+}
+
+func (r *Reader) verify() error {
+	if r.config.Hash == nil || len(r.config.Checksum) == 0 {
+		return io.EOF
+	}
+	if !bytes.Equal(r.config.Hash.Sum(nil), r.config.Checksum) {
+		return ErrChecksumMismatch
+	}
+
+	return io.EOF
+}
+
+func (r *Reader) closeBody() {
+	if r.body != nil {
+		_ = r.body.Close()
+		r.body = nil
+	}
+}
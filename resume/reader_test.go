@@ -0,0 +1,232 @@
+package resume_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+	"github.com/jdvr/go-again/resume"
+)
+
+var testPolicy = again.ConstantDelay(time.Millisecond, time.Second)
+
+// flakySource returns readers failing after failAfter bytes.
+type flakySource struct {
+	content   []byte
+	failAfter int
+	offsets   []int64
+}
+
+func (s *flakySource) open(_ context.Context, offset int64) (io.ReadCloser, error) {
+	s.offsets = append(s.offsets, offset)
+	end := int(offset) + s.failAfter
+	if end > len(s.content) {
+		return io.NopCloser(bytes.NewReader(s.content[offset:])), nil
+	}
+
+	return io.NopCloser(io.MultiReader(
+		bytes.NewReader(s.content[offset:end]),
+		errorReader{err: errors.New("connection reset")},
+	)), nil
+}
+
+type errorReader struct {
+	err error
+}
+
+func (e errorReader) Read([]byte) (int, error) {
+	return 0, e.err
+}
+
+func TestReader(t *testing.T) {
+	content := []byte("the quick brown fox jumps over the lazy dog")
+
+	t.Run("stream continues from the current offset", func(t *testing.T) {
+		source := &flakySource{content: content, failAfter: 10}
+		reader := resume.NewReader(context.Background(), source.open, resume.Config{Policy: testPolicy})
+
+		read, err := io.ReadAll(reader)
+		require.NoError(t, err)
+
+		require.Equal(t, content, read)
+		require.Equal(t, []int64{0, 10, 20, 30, 40}, source.offsets)
+		require.EqualValues(t, len(content), reader.Offset())
+		require.NoError(t, reader.Close())
+	})
+
+	t.Run("checksum is verified at the end", func(t *testing.T) {
+		sum := sha256.Sum256(content)
+		source := &flakySource{content: content, failAfter: 7}
+		reader := resume.NewReader(context.Background(), source.open, resume.Config{
+			Policy:   testPolicy,
+			Hash:     sha256.New(),
+			Checksum: sum[:],
+		})
+
+		read, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, content, read)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		source := &flakySource{content: content, failAfter: 7}
+		reader := resume.NewReader(context.Background(), source.open, resume.Config{
+			Policy:   testPolicy,
+			Hash:     sha256.New(),
+			Checksum: []byte("wrong"),
+		})
+
+		_, err := io.ReadAll(reader)
+		require.ErrorIs(t, err, resume.ErrChecksumMismatch)
+	})
+
+	t.Run("permanent open errors stop reading", func(t *testing.T) {
+		expectedErr := errors.New("not found")
+		opened := 0
+		reader := resume.NewReader(context.Background(), func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+			opened++
+			return nil, again.NewPermanentError(expectedErr)
+		}, resume.Config{Policy: testPolicy})
+
+		_, err := io.ReadAll(reader)
+		require.Equal(t, expectedErr, err)
+		require.Equal(t, 1, opened)
+
+		_, err = reader.Read(make([]byte, 1))
+		require.Equal(t, expectedErr, err)
+	})
+
+	t.Run("read fails when the policy gives up", func(t *testing.T) {
+		source := &flakySource{content: content, failAfter: 0}
+		reader := resume.NewReader(context.Background(), source.open, resume.Config{
+			Policy: again.ConstantDelay(time.Millisecond, 5*time.Millisecond),
+		})
+
+		_, err := io.ReadAll(reader)
+		require.EqualError(t, err, "connection reset")
+	})
+}
+
+func TestHTTPRange(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100)
+
+	t.Run("download resumes with range requests", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				// announce the full content but only send half of it
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write(content[:len(content)/2])
+				return
+			}
+			http.ServeContent(w, r, "content", time.Time{}, bytes.NewReader(content))
+		}))
+		defer server.Close()
+
+		reader := resume.NewReader(context.Background(), resume.HTTPRange(server.Client(), server.URL), resume.Config{
+			Policy: testPolicy,
+		})
+		defer reader.Close()
+
+		read, err := io.ReadAll(reader)
+		require.NoError(t, err)
+
+		require.Equal(t, content, read)
+		require.EqualValues(t, 2, requests.Load())
+	})
+
+	t.Run("connection dropped before the end of a complete response", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				// send the whole content but drop the connection before the response ends
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)+1))
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write(content)
+				return
+			}
+			http.ServeContent(w, r, "content", time.Time{}, bytes.NewReader(content))
+		}))
+		defer server.Close()
+
+		reader := resume.NewReader(context.Background(), resume.HTTPRange(server.Client(), server.URL), resume.Config{
+			Policy: testPolicy,
+		})
+		defer reader.Close()
+
+		read, err := io.ReadAll(reader)
+		require.NoError(t, err)
+
+		require.Equal(t, content, read)
+		require.EqualValues(t, 2, requests.Load())
+	})
+
+	t.Run("server errors are retried", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			http.ServeContent(w, r, "content", time.Time{}, bytes.NewReader(content))
+		}))
+		defer server.Close()
+
+		reader := resume.NewReader(context.Background(), resume.HTTPRange(nil, server.URL), resume.Config{
+			Policy: testPolicy,
+		})
+		defer reader.Close()
+
+		read, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, content, read)
+	})
+
+	t.Run("client errors stop the download", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		reader := resume.NewReader(context.Background(), resume.HTTPRange(nil, server.URL), resume.Config{
+			Policy: testPolicy,
+		})
+
+		_, err := io.ReadAll(reader)
+		require.ErrorContains(t, err, "404")
+	})
+
+	t.Run("servers ignoring ranges can't resume", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(content)
+		}))
+		defer server.Close()
+
+		_, err := resume.HTTPRange(nil, server.URL)(context.Background(), 10)
+		require.ErrorIs(t, err, resume.ErrRangeNotSupported)
+	})
+
+	t.Run("servers answering another range can't resume", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(content)-1, len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(content)
+		}))
+		defer server.Close()
+
+		_, err := resume.HTTPRange(nil, server.URL)(context.Background(), 10)
+		require.ErrorIs(t, err, resume.ErrUnexpectedRange)
+		require.ErrorContains(t, err, "got range starting at 0, want 10")
+	})
+}
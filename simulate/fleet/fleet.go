@@ -0,0 +1,323 @@
+// Package fleet simulates many clients retrying with go-again policies against a backend with limited capacity,
+// to evaluate how a policy behaves during and after an outage. Simulations are discrete-event and deterministic
+// for a given seed.
+package fleet
+
+import (
+	"container/heap"
+	"math/rand"
+	"time"
+
+	"github.com/jdvr/go-again"
+	"github.com/jdvr/go-again/simulate"
+)
+
+const defaultBucket = time.Second
+
+// Jitter spreads a delay returned by the policy.
+type Jitter func(delay time.Duration, random *rand.Rand) time.Duration
+
+var (
+	// NoJitter keeps the policy delays as they are.
+	NoJitter Jitter = func(delay time.Duration, _ *rand.Rand) time.Duration {
+		return delay
+	}
+	// FullJitter waits a random duration between zero and the policy delay.
+	FullJitter Jitter = func(delay time.Duration, random *rand.Rand) time.Duration {
+		return time.Duration(random.Int63n(int64(delay) + 1))
+	}
+	// EqualJitter waits half the policy delay plus a random duration up to the other half.
+	EqualJitter Jitter = func(delay time.Duration, random *rand.Rand) time.Duration {
+		return delay/2 + time.Duration(random.Int63n(int64(delay/2)+1))
+	}
+)
+
+// Budget limits retries of every client to a ratio of its requests.
+type Budget struct {
+	// Ratio of retries earned by every new request, 0.1 allows one retry every ten requests
+	Ratio float64
+	// Burst is the max number of retries saved by a client, also its initial amount
+	Burst float64
+}
+
+// Server models the backend the clients call.
+type Server struct {
+	// Capacity is the number of attempts served every second, the following ones fail.
+	// A zero capacity serves nothing: every attempt fails
+	Capacity int
+	// Latency of every response
+	Latency time.Duration
+	// OutageStart and OutageEnd delimit the window where every attempt fails
+	OutageStart, OutageEnd time.Duration
+}
+
+// Config Set values for a fleet simulation.
+type Config struct {
+	// Clients calling the server
+	Clients int
+	// RequestInterval between new requests of every client, clients start at a random offset within it
+	RequestInterval time.Duration
+	// Duration of the simulation, no new request starts after it
+	Duration time.Duration
+	// Calculator is the policy every request retries with
+	Calculator simulate.Calculator
+	// Jitter applied to the policy delays, NoJitter when nil
+	Jitter Jitter
+	// Budget limits retries per client, retries are unlimited when nil
+	Budget *Budget
+	// Server the clients call
+	Server Server
+	// Bucket is the width of the report time series, one second by default
+	Bucket time.Duration
+	// Seed makes the simulation reproducible
+	Seed int64
+}
+
+// Bucket counts the attempts that reached the server during a slice of time.
+type Bucket struct {
+	// Start of the bucket since the simulation start
+	Start time.Duration
+	// Offered is the number of attempts sent
+	Offered int
+	// Retries is the number of offered attempts that were retries
+	Retries int
+	// Served is the number of successful attempts
+	Served int
+}
+
+// Report summarizes a fleet simulation.
+type Report struct {
+	// Load is the offered load over time
+	Load []Bucket
+	// Requests started by the clients
+	Requests int
+	// Succeeded requests, possibly after retrying
+	Succeeded int
+	// GaveUp requests because the policy stopped or the budget ran out
+	GaveUp int
+	// BudgetExhausted requests that gave up because the client retry budget ran out
+	BudgetExhausted int
+	// PeakOffered is the highest number of attempts offered in a bucket
+	PeakOffered int
+	// Recovered tells whether the server served every offered attempt in a bucket after the outage
+	Recovered bool
+	// RecoveryTime from the outage end until the start of the first bucket where every offered attempt was served
+	RecoveryTime time.Duration
+}
+
+// SuccessRate is the ratio of succeeded requests.
+func (r Report) SuccessRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+
+	return float64(r.Succeeded) / float64(r.Requests)
+}
+
+// Run simulates the fleet.
+// It panics if Clients, RequestInterval or Duration are not positive or Calculator is not set.
+func Run(config Config) Report {
+	if config.Clients <= 0 || config.RequestInterval <= 0 || config.Duration <= 0 {
+		panic("fleet: Run: clients, request interval and duration must be positive")
+	}
+	if config.Calculator == nil {
+		panic("fleet: Run: nil Calculator")
+	}
+	if config.Bucket <= 0 {
+		config.Bucket = defaultBucket
+	}
+	if config.Jitter == nil {
+		config.Jitter = NoJitter
+	}
+
+	s := &simulation{
+		config: config,
+		clock:  &virtualClock{now: time.Unix(0, 0), random: rand.New(rand.NewSource(config.Seed))},
+		tokens: make([]float64, config.Clients),
+		served: map[int64]int{},
+	}
+	s.run()
+
+	return s.report
+}
+
+type eventKind int
+
+const (
+	newRequest eventKind = iota
+	sendAttempt
+	receiveResponse
+)
+
+type event struct {
+	at       time.Duration
+	sequence int
+	kind     eventKind
+	request  *request
+	client   int
+	success  bool
+}
+
+type request struct {
+	client   int
+	attempts int
+	ticks    again.TicksCalculator
+}
+
+type simulation struct {
+	config Config
+	clock  *virtualClock
+	events eventQueue
+	// sequence orders simultaneous events by scheduling order
+	sequence int
+	tokens   []float64
+	// served counts served attempts per second
+	served map[int64]int
+	report Report
+}
+
+func (s *simulation) run() {
+	for client := 0; client < s.config.Clients; client++ {
+		s.tokens[client] = s.burst()
+		offset := time.Duration(s.clock.random.Int63n(int64(s.config.RequestInterval) + 1))
+		s.schedule(event{at: offset, kind: newRequest, client: client})
+	}
+
+	for s.events.Len() > 0 {
+		e := heap.Pop(&s.events).(event)
+		s.clock.now = time.Unix(0, 0).Add(e.at)
+		switch e.kind {
+		case newRequest:
+			s.startRequest(e)
+		case sendAttempt:
+			s.send(e)
+		case receiveResponse:
+			s.receive(e)
+		}
+	}
+
+	s.summarize()
+}
+
+func (s *simulation) startRequest(e event) {
+	if e.at > s.config.Duration {
+		return
+	}
+	s.report.Requests++
+	if budget := s.config.Budget; budget != nil {
+		s.tokens[e.client] = min(s.tokens[e.client]+budget.Ratio, s.burst())
+	}
+
+	r := &request{client: e.client, ticks: s.config.Calculator(s.clock, s.clock.random.Float64)}
+	r.ticks.Reset()
+	s.schedule(event{at: e.at, kind: sendAttempt, request: r})
+	s.schedule(event{at: e.at + s.config.RequestInterval, kind: newRequest, client: e.client})
+}
+
+func (s *simulation) send(e event) {
+	e.request.attempts++
+	bucket := s.bucket(e.at)
+	bucket.Offered++
+	if e.request.attempts > 1 {
+		bucket.Retries++
+	}
+
+	server := s.config.Server
+	second := int64(e.at / time.Second)
+	success := (e.at < server.OutageStart || e.at >= server.OutageEnd) && s.served[second] < server.Capacity
+	if success {
+		s.served[second]++
+		bucket.Served++
+	}
+
+	s.schedule(event{at: e.at + server.Latency, kind: receiveResponse, request: e.request, success: success})
+}
+
+func (s *simulation) receive(e event) {
+	r := e.request
+	if e.success {
+		s.report.Succeeded++
+		return
+	}
+	tick := r.ticks.Next()
+	if tick.Stop {
+		s.report.GaveUp++
+		return
+	}
+	if s.config.Budget != nil {
+		if s.tokens[r.client] < 1 {
+			s.report.GaveUp++
+			s.report.BudgetExhausted++
+			return
+		}
+		s.tokens[r.client]--
+	}
+
+	s.schedule(event{at: e.at + s.config.Jitter(tick.Next, s.clock.random), kind: sendAttempt, request: r})
+}
+
+func (s *simulation) summarize() {
+	outageEnd := s.config.Server.OutageEnd
+	for _, bucket := range s.report.Load {
+		s.report.PeakOffered = max(s.report.PeakOffered, bucket.Offered)
+		if !s.report.Recovered && bucket.Start >= outageEnd && bucket.Offered == bucket.Served {
+			s.report.Recovered = true
+			s.report.RecoveryTime = bucket.Start - outageEnd
+		}
+	}
+}
+
+func (s *simulation) bucket(at time.Duration) *Bucket {
+	index := int(at / s.config.Bucket)
+	for len(s.report.Load) <= index {
+		s.report.Load = append(s.report.Load, Bucket{Start: time.Duration(len(s.report.Load)) * s.config.Bucket})
+	}
+
+	return &s.report.Load[index]
+}
+
+func (s *simulation) burst() float64 {
+	if s.config.Budget == nil {
+		return 0
+	}
+
+	return s.config.Budget.Burst
+}
+
+func (s *simulation) schedule(e event) {
+	e.sequence = s.sequence
+	s.sequence++
+	heap.Push(&s.events, e)
+}
+
+type eventQueue []event
+
+func (q eventQueue) Len() int { return len(q) }
+
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].at != q[j].at {
+		return q[i].at < q[j].at
+	}
+	return q[i].sequence < q[j].sequence
+}
+
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *eventQueue) Push(x any) { *q = append(*q, x.(event)) }
+
+func (q *eventQueue) Pop() any {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
+
+// virtualClock is moved by the simulation to the time of the current event, it holds the seeded source of the run.
+type virtualClock struct {
+	now    time.Time
+	random *rand.Rand
+}
+
+func (c *virtualClock) Now() time.Time {
+	return c.now
+}
@@ -0,0 +1,134 @@
+package fleet_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+	"github.com/jdvr/go-again/simulate/fleet"
+)
+
+var fleetPolicy = again.ExponentialBackoff(again.BackoffConfiguration{
+	InitialInterval:      100 * time.Millisecond,
+	IntervalMultiplier:   2,
+	MaxInterval:          10 * time.Second,
+	Timeout:              15 * time.Second,
+	DisableRandomization: true,
+})
+
+func givenConfig() fleet.Config {
+	return fleet.Config{
+		Clients:         100,
+		RequestInterval: time.Second,
+		Duration:        2 * time.Minute,
+		Calculator:      fleetPolicy.NewTicksCalculatorWithRandom,
+		Server: fleet.Server{
+			Capacity:    150,
+			Latency:     10 * time.Millisecond,
+			OutageStart: 30 * time.Second,
+			OutageEnd:   50 * time.Second,
+		},
+		Seed: 7,
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("same seed gives the same report", func(t *testing.T) {
+		config := givenConfig()
+		config.Jitter = fleet.FullJitter
+
+		require.Equal(t, fleet.Run(config), fleet.Run(config))
+	})
+
+	t.Run("healthy server serves every request", func(t *testing.T) {
+		config := givenConfig()
+		config.Server.OutageStart, config.Server.OutageEnd = 0, 0
+
+		report := fleet.Run(config)
+
+		require.Equal(t, 1.0, report.SuccessRate())
+		require.GreaterOrEqual(t, report.Requests, 100*120)
+		require.True(t, report.Recovered)
+		require.Zero(t, report.RecoveryTime)
+		for _, bucket := range report.Load {
+			require.Zero(t, bucket.Retries)
+		}
+	})
+
+	t.Run("retries build up load during the outage", func(t *testing.T) {
+		report := fleet.Run(givenConfig())
+
+		require.Greater(t, report.PeakOffered, 150)
+		require.Less(t, report.SuccessRate(), 1.0)
+		require.True(t, report.Recovered)
+		outage := report.Load[40]
+		require.Zero(t, outage.Served)
+		require.Greater(t, outage.Retries, 0)
+	})
+
+	t.Run("budget limits retry load", func(t *testing.T) {
+		unlimited := fleet.Run(givenConfig())
+		config := givenConfig()
+		config.Budget = &fleet.Budget{Ratio: 0.1, Burst: 2}
+
+		budgeted := fleet.Run(config)
+
+		require.Less(t, budgeted.PeakOffered, unlimited.PeakOffered)
+		require.Greater(t, budgeted.BudgetExhausted, 0)
+		require.LessOrEqual(t, budgeted.RecoveryTime, unlimited.RecoveryTime)
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		config := givenConfig()
+		config.Calculator = fleetPolicy.With(again.WithMaxAttempts(1)).NewTicksCalculatorWithRandom
+
+		report := fleet.Run(config)
+
+		for _, bucket := range report.Load {
+			require.Zero(t, bucket.Retries)
+		}
+		require.Equal(t, report.Requests, report.Succeeded+report.GaveUp)
+	})
+
+	t.Run("panics for invalid config", func(t *testing.T) {
+		for name, invalid := range map[string]func(config *fleet.Config){
+			"no clients":          func(config *fleet.Config) { config.Clients = 0 },
+			"no request interval": func(config *fleet.Config) { config.RequestInterval = 0 },
+			"no duration":         func(config *fleet.Config) { config.Duration = 0 },
+			"no calculator":       func(config *fleet.Config) { config.Calculator = nil },
+		} {
+			config := givenConfig()
+			invalid(&config)
+
+			require.Panics(t, func() { fleet.Run(config) }, name)
+		}
+	})
+
+	t.Run("zero capacity fails every attempt", func(t *testing.T) {
+		config := givenConfig()
+		config.Duration = 10 * time.Second
+		config.Server.Capacity = 0
+
+		report := fleet.Run(config)
+
+		require.Zero(t, report.Succeeded)
+		require.Equal(t, report.Requests, report.GaveUp)
+	})
+}
+
+func TestReport_WriteCSV(t *testing.T) {
+	config := givenConfig()
+	config.Duration = 2 * time.Second
+	config.Server.OutageStart, config.Server.OutageEnd = 0, 0
+
+	var out bytes.Buffer
+	require.NoError(t, fleet.Run(config).WriteCSV(&out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Equal(t, "start_s,offered,retries,served", lines[0])
+	require.Equal(t, "0.000,100,0,100", lines[1])
+}
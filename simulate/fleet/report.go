@@ -0,0 +1,24 @@
+package fleet
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// WriteCSV writes the offered load over time as CSV, bucket starts in seconds.
+func (r Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"start_s", "offered", "retries", "served"})
+	for _, bucket := range r.Load {
+		_ = cw.Write([]string{
+			strconv.FormatFloat(bucket.Start.Seconds(), 'f', 3, 64),
+			strconv.Itoa(bucket.Offered),
+			strconv.Itoa(bucket.Retries),
+			strconv.Itoa(bucket.Served),
+		})
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
@@ -0,0 +1,61 @@
+package simulate
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// WriteText writes a human readable summary of the report followed by the delay distribution of every retry.
+func (r Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "runs\t%d\t\n", len(r.Runs))
+	fmt.Fprintf(tw, "attempts\tmin %d\tp50 %d\tp90 %d\tp99 %d\tmax %d\t\n",
+		r.Attempts.Min, r.Attempts.P50, r.Attempts.P90, r.Attempts.P99, r.Attempts.Max)
+	fmt.Fprintf(tw, "elapsed\tmin %s\tp50 %s\tp90 %s\tp99 %s\tmax %s\t\n",
+		round(r.Elapsed.Min), round(r.Elapsed.P50), round(r.Elapsed.P90), round(r.Elapsed.P99), round(r.Elapsed.Max))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "retry\truns\tmin\tp50\tp90\tp99\tmax\t")
+	for _, delay := range r.Delays {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\t%s\t\n", delay.Retry, delay.Runs,
+			round(delay.Min), round(delay.P50), round(delay.P90), round(delay.P99), round(delay.Max))
+	}
+
+	return tw.Flush()
+}
+
+// WriteCSV writes the delay distribution of every retry as CSV, durations in milliseconds.
+func (r Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"retry", "runs", "min_ms", "p50_ms", "p90_ms", "p99_ms", "max_ms"})
+	for _, delay := range r.Delays {
+		_ = cw.Write([]string{
+			strconv.Itoa(delay.Retry),
+			strconv.Itoa(delay.Runs),
+			milliseconds(delay.Min),
+			milliseconds(delay.P50),
+			milliseconds(delay.P90),
+			milliseconds(delay.P99),
+			milliseconds(delay.Max),
+		})
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
+
+func milliseconds(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
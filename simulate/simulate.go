@@ -0,0 +1,190 @@
+// Package simulate previews what a retry policy produces by running its ticks calculator against
+// a virtual clock, without sleeping.
+package simulate
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/jdvr/go-again"
+)
+
+const (
+	defaultRuns = 1000
+	// maxSimulatedAttempts guards against calculators that never stop.
+	maxSimulatedAttempts = 10_000
+)
+
+// Calculator builds the ticks calculator to simulate, reading time from clock and jitter from random.
+// Policy.NewTicksCalculatorWithRandom fits, including the max attempts of the policy.
+type Calculator func(clock again.Clock, random again.Random) again.TicksCalculator
+
+// Latency returns how long the given attempt of the simulated operation takes.
+type Latency func(attempt int, random *rand.Rand) time.Duration
+
+// FixedLatency makes every attempt take latency.
+func FixedLatency(latency time.Duration) Latency {
+	return func(int, *rand.Rand) time.Duration {
+		return latency
+	}
+}
+
+// UniformLatency makes every attempt take a random duration between min and max.
+func UniformLatency(min, max time.Duration) Latency {
+	return func(_ int, random *rand.Rand) time.Duration {
+		return min + time.Duration(random.Int63n(int64(max-min)+1))
+	}
+}
+
+// Config Set values for a simulation.
+type Config struct {
+	// Calculator is the simulated policy
+	Calculator Calculator
+	// Runs is the number of simulated retry calls, 1000 by default
+	Runs int
+	// Latency of every attempt, attempts are instantaneous when nil
+	Latency Latency
+	// Seed makes jitter and latency reproducible
+	Seed int64
+}
+
+// Run is a simulated retry call where every attempt fails until the policy gives up.
+type Run struct {
+	// Delays waited before every retry
+	Delays []time.Duration
+	// Attempts is the number of times the operation ran
+	Attempts int
+	// Elapsed is the time from the first attempt start to giving up
+	Elapsed time.Duration
+	// Slept is the time spent waiting between attempts
+	Slept time.Duration
+}
+
+// Percentiles summarizes a distribution of samples.
+type Percentiles[T int | time.Duration] struct {
+	Min, P50, P90, P99, Max T
+}
+
+// Report summarizes the simulated runs.
+type Report struct {
+	Runs []Run
+	// Attempts distribution over the runs
+	Attempts Percentiles[int]
+	// Elapsed distribution over the runs
+	Elapsed Percentiles[time.Duration]
+	// Delays has the distribution of the delay before every retry, over the runs reaching it
+	Delays []DelayPercentiles
+}
+
+// DelayPercentiles is the delay distribution before a given retry.
+type DelayPercentiles struct {
+	// Retry is the 1-based retry number
+	Retry int
+	// Runs is the number of runs reaching this retry
+	Runs int
+	Percentiles[time.Duration]
+}
+
+// Schedule runs the calculator once with its randomness taken from seed and returns the resulting run.
+func Schedule(calculator Calculator, seed int64) Run {
+	clock := newVirtualClock(seed)
+	return simulateRun(calculator, nil, clock)
+}
+
+// Simulate runs the configured calculator many times and summarizes the runs.
+func Simulate(config Config) Report {
+	if config.Runs <= 0 {
+		config.Runs = defaultRuns
+	}
+	clock := newVirtualClock(config.Seed)
+
+	report := Report{Runs: make([]Run, 0, config.Runs)}
+	attempts := make([]int, 0, config.Runs)
+	elapsed := make([]time.Duration, 0, config.Runs)
+	var delays [][]time.Duration
+	for i := 0; i < config.Runs; i++ {
+		run := simulateRun(config.Calculator, config.Latency, clock)
+		report.Runs = append(report.Runs, run)
+		attempts = append(attempts, run.Attempts)
+		elapsed = append(elapsed, run.Elapsed)
+		for retry, delay := range run.Delays {
+			if retry == len(delays) {
+				delays = append(delays, nil)
+			}
+			delays[retry] = append(delays[retry], delay)
+		}
+	}
+
+	report.Attempts = percentiles(attempts)
+	report.Elapsed = percentiles(elapsed)
+	for retry, samples := range delays {
+		report.Delays = append(report.Delays, DelayPercentiles{
+			Retry:       retry + 1,
+			Runs:        len(samples),
+			Percentiles: percentiles(samples),
+		})
+	}
+
+	return report
+}
+
+func simulateRun(calculator Calculator, latency Latency, clock *virtualClock) Run {
+	startAt := clock.now
+	ticks := calculator(clock, clock.random.Float64)
+	ticks.Reset()
+
+	var run Run
+	for run.Attempts < maxSimulatedAttempts {
+		run.Attempts++
+		if latency != nil {
+			clock.now = clock.now.Add(latency(run.Attempts, clock.random))
+		}
+		tick := ticks.Next()
+		if tick.Stop {
+			break
+		}
+		run.Delays = append(run.Delays, tick.Next)
+		run.Slept += tick.Next
+		clock.now = clock.now.Add(tick.Next)
+	}
+	run.Elapsed = clock.now.Sub(startAt)
+
+	return run
+}
+
+// virtualClock only moves forward when the simulation advances it, it holds the seeded source of the run.
+type virtualClock struct {
+	now    time.Time
+	random *rand.Rand
+}
+
+func newVirtualClock(seed int64) *virtualClock {
+	return &virtualClock{
+		now:    time.Unix(0, 0),
+		random: rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (c *virtualClock) Now() time.Time {
+	return c.now
+}
+
+func percentiles[T int | time.Duration](samples []T) Percentiles[T] {
+	if len(samples) == 0 {
+		return Percentiles[T]{}
+	}
+	sorted := append([]T(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	at := func(p float64) T {
+		return sorted[int(p*float64(len(sorted)-1)+0.5)]
+	}
+
+	return Percentiles[T]{
+		Min: sorted[0],
+		P50: at(0.50),
+		P90: at(0.90),
+		P99: at(0.99),
+		Max: sorted[len(sorted)-1],
+	}
+}
@@ -0,0 +1,117 @@
+package simulate_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+	"github.com/jdvr/go-again/simulate"
+)
+
+func TestSchedule(t *testing.T) {
+	t.Run("exponential backoff without jitter", func(t *testing.T) {
+		policy := again.ExponentialBackoff(again.BackoffConfiguration{
+			InitialInterval:      200 * time.Millisecond,
+			IntervalMultiplier:   2,
+			MaxInterval:          time.Second,
+			Timeout:              3 * time.Second,
+			DisableRandomization: true,
+		})
+
+		run := simulate.Schedule(policy.NewTicksCalculatorWithRandom, 0)
+
+		require.Equal(t, []time.Duration{
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+			time.Second,
+			time.Second,
+		}, run.Delays)
+		require.Equal(t, 6, run.Attempts)
+		require.Equal(t, 3400*time.Millisecond, run.Slept)
+		require.Equal(t, run.Slept, run.Elapsed)
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		policy := again.ConstantDelay(time.Second, time.Hour, again.WithMaxAttempts(3))
+
+		run := simulate.Schedule(policy.NewTicksCalculatorWithRandom, 0)
+
+		require.Equal(t, 3, run.Attempts)
+		require.Equal(t, 2*time.Second, run.Elapsed)
+	})
+}
+
+func TestSimulate(t *testing.T) {
+	policy := again.ExponentialBackoff(again.BackoffConfiguration{
+		InitialInterval:    200 * time.Millisecond,
+		IntervalMultiplier: 2,
+		Timeout:            45 * time.Second,
+	})
+	config := simulate.Config{
+		Calculator: policy.NewTicksCalculatorWithRandom,
+		Runs:       500,
+		Latency:    simulate.UniformLatency(10*time.Millisecond, 50*time.Millisecond),
+		Seed:       42,
+	}
+
+	t.Run("same seed gives the same report", func(t *testing.T) {
+		require.Equal(t, simulate.Simulate(config), simulate.Simulate(config))
+	})
+
+	t.Run("jitter spreads delays around the expected interval", func(t *testing.T) {
+		report := simulate.Simulate(config)
+
+		require.Len(t, report.Runs, 500)
+		first := report.Delays[0]
+		require.Equal(t, 1, first.Retry)
+		require.Equal(t, 500, first.Runs)
+		require.GreaterOrEqual(t, first.Min, 100*time.Millisecond)
+		require.LessOrEqual(t, first.Max, 300*time.Millisecond)
+		require.Less(t, first.Min, first.Max)
+		require.LessOrEqual(t, report.Attempts.Min, report.Attempts.P50)
+		require.LessOrEqual(t, report.Attempts.P50, report.Attempts.Max)
+		require.Greater(t, report.Elapsed.Min, 45*time.Second)
+	})
+
+	t.Run("latency adds to elapsed time", func(t *testing.T) {
+		report := simulate.Simulate(simulate.Config{
+			Calculator: again.ConstantDelay(time.Second, time.Hour, again.WithMaxAttempts(3)).NewTicksCalculatorWithRandom,
+			Runs:       10,
+			Latency:    simulate.FixedLatency(100 * time.Millisecond),
+		})
+
+		require.Equal(t, 2300*time.Millisecond, report.Elapsed.P50)
+		require.Equal(t, 3, report.Attempts.Max)
+	})
+}
+
+func TestReport(t *testing.T) {
+	report := simulate.Simulate(simulate.Config{
+		Calculator: again.ConstantDelay(time.Second, time.Hour, again.WithMaxAttempts(3)).NewTicksCalculatorWithRandom,
+		Runs:       10,
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, report.WriteText(&out))
+
+		require.Contains(t, out.String(), "runs")
+		require.Contains(t, out.String(), "p99 2s")
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, report.WriteCSV(&out))
+
+		require.Equal(t, []string{
+			"retry,runs,min_ms,p50_ms,p90_ms,p99_ms,max_ms",
+			"1,10,1000.000,1000.000,1000.000,1000.000,1000.000",
+			"2,10,1000.000,1000.000,1000.000,1000.000,1000.000",
+		}, strings.Split(strings.TrimSpace(out.String()), "\n"))
+	})
+}
@@ -0,0 +1,161 @@
+package again
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/jdvr/go-again/internal"
+)
+
+// Duration is a time.Duration encoded in JSON and YAML as a human readable string such as "500ms" or "2m".
+type Duration = internal.Duration
+
+// Strategies supported by PolicySpec.
+const (
+	StrategyExponential = "exponential"
+	StrategyConstant    = "constant"
+	StrategyLinear      = "linear"
+)
+
+// PolicySpec is the declarative form of a Policy, meant to be loaded from JSON, YAML or environment variables.
+type PolicySpec struct {
+	// Strategy is one of "exponential", "constant" or "linear", exponential is used when empty
+	Strategy string `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	// InitialInterval is the first delay of the exponential and linear strategies
+	InitialInterval Duration `json:"initialInterval,omitempty" yaml:"initialInterval,omitempty"`
+	// MaxInterval caps exponential delays
+	MaxInterval Duration `json:"maxInterval,omitempty" yaml:"maxInterval,omitempty"`
+	// Multiplier grows exponential delays
+	Multiplier float64 `json:"multiplier,omitempty" yaml:"multiplier,omitempty"`
+	// Delay between retries of the constant strategy
+	Delay Duration `json:"delay,omitempty" yaml:"delay,omitempty"`
+	// Increment added to every linear delay
+	Increment Duration `json:"increment,omitempty" yaml:"increment,omitempty"`
+	// Timeout is the max duration of the retry process
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// MaxAttempts stops retrying after this number of attempts, 0 means no limit
+	MaxAttempts int `json:"maxAttempts,omitempty" yaml:"maxAttempts,omitempty"`
+	// DisableJitter makes exponential delays predictable, other strategies have no jitter
+	DisableJitter bool `json:"disableJitter,omitempty" yaml:"disableJitter,omitempty"`
+	// RetryableErrors are regular expressions matched against error messages, when set any other error
+	// stops retrying
+	RetryableErrors []string `json:"retryableErrors,omitempty" yaml:"retryableErrors,omitempty"`
+}
+
+// Policy validates the spec and builds the policy it describes, extra options are applied after the spec ones.
+func (s PolicySpec) Policy(options ...Option) (Policy, error) {
+	var specOptions []Option
+	if s.MaxAttempts < 0 {
+		return Policy{}, fmt.Errorf("again: negative max attempts %d", s.MaxAttempts)
+	}
+	if s.MaxAttempts > 0 {
+		specOptions = append(specOptions, WithMaxAttempts(s.MaxAttempts))
+	}
+	if len(s.RetryableErrors) > 0 {
+		patterns := make([]*regexp.Regexp, 0, len(s.RetryableErrors))
+		for _, expr := range s.RetryableErrors {
+			pattern, err := regexp.Compile(expr)
+			if err != nil {
+				return Policy{}, fmt.Errorf("again: invalid retryable error pattern: %w", err)
+			}
+			patterns = append(patterns, pattern)
+		}
+		specOptions = append(specOptions, WithRetryIf(func(err error) bool {
+			for _, pattern := range patterns {
+				if pattern.MatchString(err.Error()) {
+					return true
+				}
+			}
+			return false
+		}))
+	}
+	options = append(specOptions, options...)
+
+	switch s.Strategy {
+	case "", StrategyExponential:
+		if s.InitialInterval < 0 || s.MaxInterval < 0 || s.Timeout < 0 {
+			return Policy{}, fmt.Errorf("again: exponential strategy intervals and timeout can't be negative")
+		}
+		if s.Multiplier != 0 && s.Multiplier < 1 {
+			return Policy{}, fmt.Errorf("again: exponential strategy needs a multiplier of at least 1, got %v", s.Multiplier)
+		}
+		return ExponentialBackoff(BackoffConfiguration{
+			InitialInterval:      time.Duration(s.InitialInterval),
+			MaxInterval:          time.Duration(s.MaxInterval),
+			IntervalMultiplier:   s.Multiplier,
+			Timeout:              time.Duration(s.Timeout),
+			DisableRandomization: s.DisableJitter,
+		}, options...), nil
+	case StrategyConstant:
+		if s.Delay <= 0 || s.Timeout <= 0 {
+			return Policy{}, fmt.Errorf("again: constant strategy needs positive delay and timeout")
+		}
+		return ConstantDelay(time.Duration(s.Delay), time.Duration(s.Timeout), options...), nil
+	case StrategyLinear:
+		if s.InitialInterval <= 0 || s.Timeout <= 0 {
+			return Policy{}, fmt.Errorf("again: linear strategy needs positive initial interval and timeout")
+		}
+		return LinearDelay(time.Duration(s.InitialInterval), time.Duration(s.Increment), time.Duration(s.Timeout), options...), nil
+	default:
+		return Policy{}, fmt.Errorf("again: unknown strategy %q", s.Strategy)
+	}
+}
+
+// WithEnv returns a copy of the spec overridden by the environment variables named prefix followed by
+// _STRATEGY, _INITIAL_INTERVAL, _MAX_INTERVAL, _MULTIPLIER, _DELAY, _INCREMENT, _TIMEOUT, _MAX_ATTEMPTS,
+// _DISABLE_JITTER or _RETRYABLE_ERRORS. Retryable errors are a JSON array such as ["timeout","\\d{1,3}"],
+// any other value is a single pattern.
+func (s PolicySpec) WithEnv(prefix string) (PolicySpec, error) {
+	var err error
+	lookup := func(name string, parse func(value string) error) {
+		value, ok := os.LookupEnv(prefix + "_" + name)
+		if !ok || err != nil {
+			return
+		}
+		if perr := parse(value); perr != nil {
+			err = fmt.Errorf("again: %s_%s: %w", prefix, name, perr)
+		}
+	}
+	duration := func(target *Duration) func(value string) error {
+		return func(value string) error {
+			return target.UnmarshalText([]byte(value))
+		}
+	}
+
+	lookup("STRATEGY", func(value string) error {
+		s.Strategy = value
+		return nil
+	})
+	lookup("INITIAL_INTERVAL", duration(&s.InitialInterval))
+	lookup("MAX_INTERVAL", duration(&s.MaxInterval))
+	lookup("MULTIPLIER", func(value string) (perr error) {
+		s.Multiplier, perr = strconv.ParseFloat(value, 64)
+		return perr
+	})
+	lookup("DELAY", duration(&s.Delay))
+	lookup("INCREMENT", duration(&s.Increment))
+	lookup("TIMEOUT", duration(&s.Timeout))
+	lookup("MAX_ATTEMPTS", func(value string) (perr error) {
+		s.MaxAttempts, perr = strconv.Atoi(value)
+		return perr
+	})
+	lookup("DISABLE_JITTER", func(value string) (perr error) {
+		s.DisableJitter, perr = strconv.ParseBool(value)
+		return perr
+	})
+	lookup("RETRYABLE_ERRORS", func(value string) error {
+		// patterns may contain any separator, a list is given as JSON
+		var patterns []string
+		if err := json.Unmarshal([]byte(value), &patterns); err != nil {
+			patterns = []string{value}
+		}
+		s.RetryableErrors = patterns
+		return nil
+	})
+
+	return s, err
+}
@@ -0,0 +1,153 @@
+package again_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jdvr/go-again"
+)
+
+func TestBackoffConfiguration_Encoding(t *testing.T) {
+	configuration := again.BackoffConfiguration{
+		InitialInterval:    500 * time.Millisecond,
+		MaxInterval:        10 * time.Second,
+		IntervalMultiplier: 2,
+		Timeout:            2 * time.Minute,
+	}
+
+	t.Run("json keeps the time.Duration encoding", func(t *testing.T) {
+		data, err := json.Marshal(configuration)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"InitialInterval":500000000,"MaxInterval":10000000000,"IntervalMultiplier":2,"Timeout":120000000000,"DisableRandomization":false}`, string(data))
+
+		var decoded again.BackoffConfiguration
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.Equal(t, configuration, decoded)
+	})
+
+	t.Run("json accepts human durations", func(t *testing.T) {
+		var decoded again.BackoffConfiguration
+		require.NoError(t, json.Unmarshal([]byte(`{"initialInterval":"500ms","maxInterval":"10s","intervalMultiplier":2,"timeout":"2m"}`), &decoded))
+		require.Equal(t, configuration, decoded)
+	})
+
+	t.Run("yaml uses human durations", func(t *testing.T) {
+		data, err := yaml.Marshal(configuration)
+		require.NoError(t, err)
+		require.Contains(t, string(data), "initialInterval: 500ms")
+
+		var decoded again.BackoffConfiguration
+		require.NoError(t, yaml.Unmarshal(data, &decoded))
+		require.Equal(t, configuration, decoded)
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		var decoded again.BackoffConfiguration
+		require.ErrorContains(t, json.Unmarshal([]byte(`{"timeout":"soon"}`), &decoded), `invalid duration "soon"`)
+	})
+}
+
+func TestPolicySpec_Policy(t *testing.T) {
+	t.Run("retryable errors stop on any other error", func(t *testing.T) {
+		policy, err := again.PolicySpec{
+			Strategy:        again.StrategyConstant,
+			Delay:           again.Duration(time.Millisecond),
+			Timeout:         again.Duration(time.Second),
+			RetryableErrors: []string{"timeout", "^unavailable"},
+		}.Policy()
+		require.NoError(t, err)
+
+		called := 0
+		err = again.Do(context.Background(), policy, func(ctx context.Context) error {
+			called++
+			if called < 3 {
+				return errors.New("unavailable: try later")
+			}
+			return errors.New("invalid request")
+		})
+
+		require.EqualError(t, err, "invalid request")
+		require.Equal(t, 3, called)
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		policy, err := again.PolicySpec{
+			Strategy:        again.StrategyLinear,
+			InitialInterval: again.Duration(time.Millisecond),
+			Timeout:         again.Duration(time.Second),
+			MaxAttempts:     2,
+		}.Policy()
+		require.NoError(t, err)
+
+		called := 0
+		_ = again.Do(context.Background(), policy, func(ctx context.Context) error {
+			called++
+			return errors.New("failing")
+		})
+
+		require.Equal(t, 2, called)
+	})
+
+	t.Run("invalid specs", func(t *testing.T) {
+		for name, spec := range map[string]again.PolicySpec{
+			"unknown strategy":              {Strategy: "fibonacci"},
+			"exponential negative interval": {InitialInterval: again.Duration(-time.Second)},
+			"exponential negative max":      {MaxInterval: again.Duration(-time.Second)},
+			"exponential negative timeout":  {Timeout: again.Duration(-time.Second)},
+			"exponential small multiplier":  {Multiplier: 0.5},
+			"constant no delay":             {Strategy: again.StrategyConstant, Timeout: again.Duration(time.Second)},
+			"linear no timeout":             {Strategy: again.StrategyLinear, InitialInterval: again.Duration(time.Second)},
+			"negative attempts":             {MaxAttempts: -1},
+			"invalid error regex":           {RetryableErrors: []string{"("}},
+		} {
+			_, err := spec.Policy()
+			require.Error(t, err, name)
+		}
+	})
+}
+
+func TestPolicySpec_WithEnv(t *testing.T) {
+	t.Run("environment overrides spec values", func(t *testing.T) {
+		t.Setenv("PAYMENTS_STRATEGY", "constant")
+		t.Setenv("PAYMENTS_DELAY", "250ms")
+		t.Setenv("PAYMENTS_MAX_ATTEMPTS", "4")
+		t.Setenv("PAYMENTS_RETRYABLE_ERRORS", `["timeout", "code \\d{1,3}"]`)
+
+		spec, err := again.PolicySpec{
+			Timeout:     again.Duration(time.Minute),
+			MaxAttempts: 10,
+		}.WithEnv("PAYMENTS")
+		require.NoError(t, err)
+
+		require.Equal(t, again.PolicySpec{
+			Strategy:        again.StrategyConstant,
+			Delay:           again.Duration(250 * time.Millisecond),
+			Timeout:         again.Duration(time.Minute),
+			MaxAttempts:     4,
+			RetryableErrors: []string{"timeout", `code \d{1,3}`},
+		}, spec)
+	})
+
+	t.Run("single retryable error pattern", func(t *testing.T) {
+		t.Setenv("PAYMENTS_RETRYABLE_ERRORS", `[45]\d{2},? retry`)
+
+		spec, err := again.PolicySpec{}.WithEnv("PAYMENTS")
+		require.NoError(t, err)
+
+		require.Equal(t, []string{`[45]\d{2},? retry`}, spec.RetryableErrors)
+	})
+
+	t.Run("invalid environment value", func(t *testing.T) {
+		t.Setenv("PAYMENTS_TIMEOUT", "forever")
+
+		_, err := again.PolicySpec{}.WithEnv("PAYMENTS")
+
+		require.ErrorContains(t, err, "PAYMENTS_TIMEOUT")
+	})
+}
@@ -0,0 +1,204 @@
+package sqlretry
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/jdvr/go-again"
+)
+
+// conn retries calls on its underlying connection, replacing it when it is bad.
+// database/sql never uses a connection concurrently so it needs no locking.
+type conn struct {
+	connector *retryConnector
+	conn      driver.Conn
+	inTx      bool
+}
+
+var (
+	_ driver.Conn               = &conn{}
+	_ driver.ConnBeginTx        = &conn{}
+	_ driver.ConnPrepareContext = &conn{}
+	_ driver.QueryerContext     = &conn{}
+	_ driver.ExecerContext      = &conn{}
+	_ driver.Pinger             = &conn{}
+	_ driver.SessionResetter    = &conn{}
+	_ driver.Validator          = &conn{}
+	_ driver.NamedValueChecker  = &conn{}
+)
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	if c.conn == nil {
+		return nil, driver.ErrBadConn
+	}
+
+	return c.conn.Prepare(query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if c.conn == nil {
+		return nil, driver.ErrBadConn
+	}
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+
+	return c.conn.Prepare(query)
+}
+
+// CheckNamedValue lets the underlying connection check arguments, falling back to the default
+// database/sql conversion when it doesn't.
+func (c *conn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+
+	return driver.ErrSkip
+}
+
+func (c *conn) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+
+	return c.conn.Close()
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx is retried as nothing ran in the transaction yet, statements inside it never are.
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	tx, err := retry(ctx, c, func(ctx context.Context) (driver.Tx, error) {
+		if beginner, ok := c.conn.(driver.ConnBeginTx); ok {
+			return beginner.BeginTx(ctx, opts)
+		}
+		return c.conn.Begin()
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.inTx = true
+
+	return &transaction{tx: tx, conn: c}, nil
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	_, err := retry(ctx, c, func(ctx context.Context) (struct{}, error) {
+		if pinger, ok := c.conn.(driver.Pinger); ok {
+			return struct{}{}, pinger.Ping(ctx)
+		}
+		return struct{}{}, nil
+	})
+
+	return err
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	run := func(ctx context.Context) (driver.Rows, error) {
+		if queryer, ok := c.conn.(driver.QueryerContext); ok {
+			return queryer.QueryContext(ctx, query, args)
+		}
+		return nil, driver.ErrSkip
+	}
+
+	return runStatement(ctx, c, c.connector.config.idempotent(query, false), run)
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	run := func(ctx context.Context) (driver.Result, error) {
+		if execer, ok := c.conn.(driver.ExecerContext); ok {
+			return execer.ExecContext(ctx, query, args)
+		}
+		return nil, driver.ErrSkip
+	}
+
+	return runStatement(ctx, c, c.connector.config.idempotent(query, true), run)
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if c.conn == nil {
+		return driver.ErrBadConn
+	}
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+// IsValid reports false once a bad underlying connection couldn't be replaced, so database/sql discards it.
+func (c *conn) IsValid() bool {
+	if c.conn == nil {
+		return false
+	}
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+
+	return true
+}
+
+// runStatement retries idempotent statements outside transactions and runs the others once.
+func runStatement[T any](ctx context.Context, c *conn, idempotent bool, fn func(ctx context.Context) (T, error)) (T, error) {
+	if idempotent && !c.inTx {
+		return retry(ctx, c, fn)
+	}
+	if c.conn == nil {
+		var zero T
+		return zero, driver.ErrBadConn
+	}
+
+	return fn(ctx)
+}
+
+// retry runs fn using the connector policy, a bad connection is replaced before the next attempt
+// and errors that are not transient stop retrying.
+func retry[T any](ctx context.Context, c *conn, fn func(ctx context.Context) (T, error)) (T, error) {
+	config := c.connector.config
+
+	return again.Get[T](ctx, config.Policy, func(ctx context.Context) (T, error) {
+		var zero T
+		if c.conn == nil {
+			underlying, err := c.connector.connector.Connect(ctx)
+			if err != nil {
+				if !config.transient(err) {
+					return zero, again.NewPermanentError(err)
+				}
+				return zero, err
+			}
+			c.conn = underlying
+		}
+
+		value, err := fn(ctx)
+		if err == nil {
+			return value, nil
+		}
+		if !config.transient(err) {
+			return value, again.NewPermanentError(err)
+		}
+		if errors.Is(err, driver.ErrBadConn) {
+			_ = c.conn.Close()
+			c.conn = nil
+		}
+
+		return value, err
+	})
+}
+
+type transaction struct {
+	tx   driver.Tx
+	conn *conn
+}
+
+func (t *transaction) Commit() error {
+	t.conn.inTx = false
+	return t.tx.Commit()
+}
+
+func (t *transaction) Rollback() error {
+	t.conn.inTx = false
+	return t.tx.Rollback()
+}
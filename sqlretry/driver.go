@@ -0,0 +1,133 @@
+// Package sqlretry wraps database/sql drivers so bad connections and transient errors are retried
+// with go-again policies.
+package sqlretry
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+
+	"github.com/jdvr/go-again"
+)
+
+// Config Set values for the driver wrapper.
+type Config struct {
+	// Policy retries connecting and running statements, each call gets a fresh policy budget
+	Policy again.Policy
+	// IsTransient classifies errors worth retrying besides driver.ErrBadConn, nil means none
+	IsTransient func(err error) bool
+	// IsIdempotent reports whether a statement can run again, when nil queries are idempotent and execs aren't
+	IsIdempotent func(query string, exec bool) bool
+}
+
+func (c Config) transient(err error) bool {
+	return errors.Is(err, driver.ErrBadConn) || (c.IsTransient != nil && c.IsTransient(err))
+}
+
+func (c Config) idempotent(query string, exec bool) bool {
+	if c.IsIdempotent != nil {
+		return c.IsIdempotent(query, exec)
+	}
+
+	return !exec
+}
+
+// ReadOnly is an IsIdempotent classifier considering SELECT statements idempotent, whatever the call used.
+func ReadOnly(query string, _ bool) bool {
+	fields := strings.Fields(query)
+
+	return len(fields) > 0 && strings.EqualFold(fields[0], "SELECT")
+}
+
+// NewConnector wraps connector, the result is meant for sql.OpenDB.
+func NewConnector(connector driver.Connector, config Config) driver.Connector {
+	return &retryConnector{
+		connector: connector,
+		driver:    &retryDriver{driver: connector.Driver(), config: config},
+		config:    config,
+	}
+}
+
+// NewDriver wraps d, the result is meant for sql.Register.
+func NewDriver(d driver.Driver, config Config) driver.Driver {
+	return &retryDriver{driver: d, config: config}
+}
+
+type retryDriver struct {
+	driver driver.Driver
+	config Config
+}
+
+var (
+	_ driver.Driver        = &retryDriver{}
+	_ driver.DriverContext = &retryDriver{}
+)
+
+func (d *retryDriver) Open(name string) (driver.Conn, error) {
+	connector, err := d.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return connector.Connect(context.Background())
+}
+
+func (d *retryDriver) OpenConnector(name string) (driver.Connector, error) {
+	var connector driver.Connector = dsnConnector{name: name, driver: d.driver}
+	if driverContext, ok := d.driver.(driver.DriverContext); ok {
+		var err error
+		if connector, err = driverContext.OpenConnector(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return &retryConnector{connector: connector, driver: d, config: d.config}, nil
+}
+
+type retryConnector struct {
+	connector driver.Connector
+	driver    driver.Driver
+	config    Config
+}
+
+var _ driver.Connector = &retryConnector{}
+
+func (c *retryConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	underlying, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{connector: c, conn: underlying}, nil
+}
+
+func (c *retryConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// connect opens an underlying connection retrying bad connections and transient errors.
+func (c *retryConnector) connect(ctx context.Context) (driver.Conn, error) {
+	return again.Get[driver.Conn](ctx, c.config.Policy, func(ctx context.Context) (driver.Conn, error) {
+		conn, err := c.connector.Connect(ctx)
+		if err != nil && !c.config.transient(err) {
+			return nil, again.NewPermanentError(err)
+		}
+
+		return conn, err
+	})
+}
+
+// dsnConnector adapts drivers without DriverContext, like database/sql does.
+type dsnConnector struct {
+	name   string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,210 @@
+package sqlretry_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+	"github.com/jdvr/go-again/internal/fakesql"
+	"github.com/jdvr/go-again/sqlretry"
+)
+
+var errTransient = errors.New("connection reset by peer")
+
+var testConfig = sqlretry.Config{
+	Policy: again.ConstantDelay(time.Millisecond, time.Second),
+	IsTransient: func(err error) bool {
+		return errors.Is(err, errTransient)
+	},
+}
+
+// registeredDrivers makes driver names unique, sql.Register panics when a name is registered twice.
+var registeredDrivers atomic.Int32
+
+// failing returns a function failing with err the first times calls.
+func failing(times int, err error) func() error {
+	return func() error {
+		if times > 0 {
+			times--
+			return err
+		}
+		return nil
+	}
+}
+
+func openDB(t *testing.T, fake *fakesql.Driver, config sqlretry.Config) *sql.DB {
+	t.Helper()
+	db := sql.OpenDB(sqlretry.NewConnector(fake, config))
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+func TestConnector(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("connect is retried on bad connections", func(t *testing.T) {
+		fake := &fakesql.Driver{OnConnect: failing(2, driver.ErrBadConn)}
+		db := openDB(t, fake, testConfig)
+
+		require.NoError(t, db.PingContext(ctx))
+
+		require.Equal(t, []string{"connect", "connect", "connect", "ping"}, fake.Calls())
+	})
+
+	t.Run("connect errors that are not transient are not retried", func(t *testing.T) {
+		fake := &fakesql.Driver{OnConnect: failing(1, errors.New("password authentication failed"))}
+		db := openDB(t, fake, testConfig)
+
+		require.EqualError(t, db.PingContext(ctx), "password authentication failed")
+
+		require.Equal(t, []string{"connect"}, fake.Calls())
+	})
+
+	t.Run("ping reconnects bad connections", func(t *testing.T) {
+		fake := &fakesql.Driver{OnPing: failing(1, driver.ErrBadConn)}
+		db := openDB(t, fake, testConfig)
+
+		require.NoError(t, db.PingContext(ctx))
+
+		require.Equal(t, []string{"connect", "ping", "close", "connect", "ping"}, fake.Calls())
+	})
+
+	t.Run("queries are retried on transient errors", func(t *testing.T) {
+		failQuery := failing(1, errTransient)
+		fake := &fakesql.Driver{
+			OnQuery: func(query string, args []driver.NamedValue) (*fakesql.Rows, error) {
+				if err := failQuery(); err != nil {
+					return nil, err
+				}
+				return &fakesql.Rows{Columns: []string{"n"}, Values: [][]driver.Value{{int64(1)}}}, nil
+			},
+		}
+		db := openDB(t, fake, testConfig)
+
+		var n int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT 1").Scan(&n))
+
+		require.Equal(t, 1, n)
+		require.Equal(t, []string{"connect", "query SELECT 1", "query SELECT 1"}, fake.Calls())
+	})
+
+	t.Run("execs are not retried by default", func(t *testing.T) {
+		failExec := failing(1, errTransient)
+		fake := &fakesql.Driver{
+			OnExec: func(query string, args []driver.NamedValue) (driver.Result, error) {
+				return driver.RowsAffected(1), failExec()
+			},
+		}
+		db := openDB(t, fake, testConfig)
+
+		_, err := db.ExecContext(ctx, "INSERT INTO t VALUES (1)")
+
+		require.ErrorIs(t, err, errTransient)
+		require.Equal(t, []string{"connect", "exec INSERT INTO t VALUES (1)"}, fake.Calls())
+	})
+
+	t.Run("idempotent execs are retried", func(t *testing.T) {
+		failExec := failing(1, errTransient)
+		fake := &fakesql.Driver{
+			OnExec: func(query string, args []driver.NamedValue) (driver.Result, error) {
+				return driver.RowsAffected(1), failExec()
+			},
+		}
+		config := testConfig
+		config.IsIdempotent = func(query string, exec bool) bool {
+			return true
+		}
+		db := openDB(t, fake, config)
+
+		_, err := db.ExecContext(ctx, "UPDATE t SET v = 1")
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"connect", "exec UPDATE t SET v = 1", "exec UPDATE t SET v = 1"}, fake.Calls())
+	})
+
+	t.Run("statements are never retried inside a transaction", func(t *testing.T) {
+		fake := &fakesql.Driver{
+			OnQuery: func(query string, args []driver.NamedValue) (*fakesql.Rows, error) {
+				return nil, errTransient
+			},
+		}
+		db := openDB(t, fake, testConfig)
+
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		_, err = tx.QueryContext(ctx, "SELECT 1")
+		require.ErrorIs(t, err, errTransient)
+		require.NoError(t, tx.Rollback())
+
+		require.Equal(t, []string{"connect", "begin", "query SELECT 1", "rollback"}, fake.Calls())
+	})
+
+	t.Run("statements are retried again after the transaction ends", func(t *testing.T) {
+		failQuery := failing(1, errTransient)
+		fake := &fakesql.Driver{
+			OnQuery: func(query string, args []driver.NamedValue) (*fakesql.Rows, error) {
+				return &fakesql.Rows{}, failQuery()
+			},
+		}
+		db := openDB(t, fake, testConfig)
+
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		rows, err := db.QueryContext(ctx, "SELECT 1")
+		require.NoError(t, err)
+		require.NoError(t, rows.Close())
+
+		require.Equal(t, []string{"connect", "begin", "commit", "query SELECT 1", "query SELECT 1"}, fake.Calls())
+	})
+}
+
+func TestReadOnly(t *testing.T) {
+	require.True(t, sqlretry.ReadOnly("  select * from t", true))
+	require.False(t, sqlretry.ReadOnly("DELETE FROM t", false))
+	require.False(t, sqlretry.ReadOnly("", false))
+}
+
+func TestNewDriver(t *testing.T) {
+	t.Run("wrapped driver can be registered", func(t *testing.T) {
+		fake := &fakesql.Driver{OnConnect: failing(1, driver.ErrBadConn)}
+		name := fmt.Sprintf("sqlretry-fake-%d", registeredDrivers.Add(1))
+		sql.Register(name, sqlretry.NewDriver(fake, testConfig))
+
+		db, err := sql.Open(name, "dsn")
+		require.NoError(t, err)
+		defer db.Close()
+
+		require.NoError(t, db.PingContext(context.Background()))
+		require.Equal(t, []string{"connect", "connect", "ping"}, fake.Calls())
+	})
+}
+
+func TestConn_CheckNamedValue(t *testing.T) {
+	t.Run("arguments are checked by the wrapped driver", func(t *testing.T) {
+		var got any
+		fake := &fakesql.Driver{OnExec: func(query string, args []driver.NamedValue) (driver.Result, error) {
+			got = args[0].Value
+			return driver.RowsAffected(1), nil
+		}}
+		db := sql.OpenDB(sqlretry.NewConnector(fake, testConfig))
+		defer db.Close()
+
+		_, err := db.ExecContext(context.Background(), "UPDATE t SET tags = $1", []string{"a", "b"})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"a", "b"}, got)
+	})
+}
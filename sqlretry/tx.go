@@ -0,0 +1,88 @@
+package sqlretry
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jdvr/go-again"
+	"github.com/jdvr/go-again/internal"
+)
+
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// ConflictClassifier reports whether a transaction failed because it conflicted with another one
+// and must run again from the beginning.
+type ConflictClassifier func(err error) bool
+
+// TxOption customizes RetryTx.
+type TxOption func(config *txConfig)
+
+type txConfig struct {
+	isConflict ConflictClassifier
+}
+
+// WithConflictClassifier replaces IsSerializationFailure to decide which errors re-run the transaction.
+func WithConflictClassifier(classifier ConflictClassifier) TxOption {
+	return func(config *txConfig) {
+		config.isConflict = classifier
+	}
+}
+
+// IsSerializationFailure reports whether err carries the serialization failure or deadlock SQLSTATE,
+// as returned by Postgres and CockroachDB drivers exposing a SQLState method.
+func IsSerializationFailure(err error) bool {
+	var stateErr interface{ SQLState() string }
+	if !errors.As(err, &stateErr) {
+		return false
+	}
+	state := stateErr.SQLState()
+
+	return state == sqlStateSerializationFailure || state == sqlStateDeadlockDetected
+}
+
+// RetryTx begins a transaction, runs fn and commits. When fn or the commit fail with a conflict the
+// transaction is rolled back and the whole function runs again using policy, other errors are returned
+// right away. fn must not commit or roll back the transaction itself, a panic of fn rolls it back.
+func RetryTx(ctx context.Context, db *sql.DB, txOpts *sql.TxOptions, policy again.Policy, fn func(ctx context.Context, tx *sql.Tx) error, options ...TxOption) error {
+	config := txConfig{isConflict: IsSerializationFailure}
+	for _, option := range options {
+		option(&config)
+	}
+
+	classify := func(err error) error {
+		var permanent *internal.PermanentError
+		if errors.As(err, &permanent) || config.isConflict(err) {
+			return err
+		}
+		return again.NewPermanentError(err)
+	}
+
+	return again.Do(ctx, policy, func(ctx context.Context) error {
+		tx, err := db.BeginTx(ctx, txOpts)
+		if err != nil {
+			return classify(err)
+		}
+		defer func() {
+			// release the connection before a panic of fn goes on
+			if value := recover(); value != nil {
+				_ = tx.Rollback()
+				panic(value)
+			}
+		}()
+
+		if err := fn(ctx, tx); err != nil {
+			_ = tx.Rollback()
+			return classify(err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return classify(err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,154 @@
+package sqlretry_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+	"github.com/jdvr/go-again/internal/fakesql"
+	"github.com/jdvr/go-again/sqlretry"
+)
+
+type sqlStateError struct {
+	state string
+}
+
+func (e sqlStateError) Error() string {
+	return "ERROR: SQLSTATE " + e.state
+}
+
+func (e sqlStateError) SQLState() string {
+	return e.state
+}
+
+func TestRetryTx(t *testing.T) {
+	ctx := context.Background()
+	policy := testConfig.Policy
+
+	t.Run("transaction is committed", func(t *testing.T) {
+		fake := &fakesql.Driver{}
+		db := sql.OpenDB(fake)
+		defer db.Close()
+
+		err := sqlretry.RetryTx(ctx, db, nil, policy, func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "UPDATE accounts SET balance = 0")
+			return err
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"connect", "begin", "exec UPDATE accounts SET balance = 0", "commit"}, fake.Calls())
+	})
+
+	t.Run("whole transaction runs again on serialization failures", func(t *testing.T) {
+		fake := &fakesql.Driver{}
+		db := sql.OpenDB(fake)
+		defer db.Close()
+		runs := 0
+
+		err := sqlretry.RetryTx(ctx, db, nil, policy, func(ctx context.Context, tx *sql.Tx) error {
+			runs++
+			if runs == 1 {
+				return fmt.Errorf("update: %w", sqlStateError{state: "40001"})
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 2, runs)
+		require.Equal(t, []string{"connect", "begin", "rollback", "begin", "commit"}, fake.Calls())
+	})
+
+	t.Run("commit conflicts run the transaction again", func(t *testing.T) {
+		fake := &fakesql.Driver{OnCommit: failing(1, sqlStateError{state: "40P01"})}
+		db := sql.OpenDB(fake)
+		defer db.Close()
+		runs := 0
+
+		err := sqlretry.RetryTx(ctx, db, nil, policy, func(ctx context.Context, tx *sql.Tx) error {
+			runs++
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 2, runs)
+	})
+
+	t.Run("other errors roll back and stop", func(t *testing.T) {
+		fake := &fakesql.Driver{}
+		db := sql.OpenDB(fake)
+		defer db.Close()
+		expectedErr := sqlStateError{state: "23505"}
+		runs := 0
+
+		err := sqlretry.RetryTx(ctx, db, nil, policy, func(ctx context.Context, tx *sql.Tx) error {
+			runs++
+			return expectedErr
+		})
+
+		require.Equal(t, expectedErr, err)
+		require.Equal(t, 1, runs)
+		require.Equal(t, []string{"connect", "begin", "rollback"}, fake.Calls())
+	})
+
+	t.Run("panics roll back", func(t *testing.T) {
+		fake := &fakesql.Driver{}
+		db := sql.OpenDB(fake)
+		defer db.Close()
+
+		require.PanicsWithValue(t, "nil map", func() {
+			_ = sqlretry.RetryTx(ctx, db, nil, policy, func(ctx context.Context, tx *sql.Tx) error {
+				panic("nil map")
+			})
+		})
+
+		require.Equal(t, []string{"connect", "begin", "rollback"}, fake.Calls())
+		require.Zero(t, db.Stats().InUse)
+	})
+
+	t.Run("permanent errors are not classified", func(t *testing.T) {
+		db := sql.OpenDB(&fakesql.Driver{})
+		defer db.Close()
+		expectedErr := sqlStateError{state: "40001"}
+		runs := 0
+
+		err := sqlretry.RetryTx(ctx, db, nil, policy, func(ctx context.Context, tx *sql.Tx) error {
+			runs++
+			return again.NewPermanentError(expectedErr)
+		})
+
+		require.Equal(t, expectedErr, err)
+		require.Equal(t, 1, runs)
+	})
+
+	t.Run("conflict classifier is pluggable", func(t *testing.T) {
+		db := sql.OpenDB(&fakesql.Driver{})
+		defer db.Close()
+		errLockTimeout := errors.New("lock wait timeout exceeded")
+		runs := 0
+
+		err := sqlretry.RetryTx(ctx, db, nil, policy, func(ctx context.Context, tx *sql.Tx) error {
+			runs++
+			if runs < 3 {
+				return errLockTimeout
+			}
+			return nil
+		}, sqlretry.WithConflictClassifier(func(err error) bool {
+			return errors.Is(err, errLockTimeout)
+		}))
+		require.NoError(t, err)
+
+		require.Equal(t, 3, runs)
+	})
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	require.True(t, sqlretry.IsSerializationFailure(sqlStateError{state: "40001"}))
+	require.True(t, sqlretry.IsSerializationFailure(fmt.Errorf("wrapped: %w", sqlStateError{state: "40P01"})))
+	require.False(t, sqlretry.IsSerializationFailure(sqlStateError{state: "23505"}))
+	require.False(t, sqlretry.IsSerializationFailure(errors.New("40001")))
+}
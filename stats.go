@@ -0,0 +1,47 @@
+package again
+
+import (
+	"context"
+
+	"github.com/jdvr/go-again/internal"
+)
+
+// Stats describes a finished retry call: attempts, elapsed time, backoff sleep, rate limiter waits,
+// the duration and error of every attempt and why it stopped.
+type Stats = internal.Stats
+
+// AttemptStats describes a finished attempt.
+type AttemptStats = internal.AttemptStats
+
+// StopReason tells why a retry call returned.
+type StopReason = internal.StopReason
+
+// Reasons a retry call returns.
+const (
+	StopSucceeded      = internal.StopSucceeded
+	StopPermanentError = internal.StopPermanentError
+	StopNotRetryable   = internal.StopNotRetryable
+	StopMaxAttempts    = internal.StopMaxAttempts
+	StopPolicy         = internal.StopPolicy
+	StopContextDone    = internal.StopContextDone
+	StopRateLimited    = internal.StopRateLimited
+)
+
+// DoWithStats is Do also returning the statistics of the call.
+func DoWithStats(ctx context.Context, policy Policy, run func(ctx context.Context) error) (Stats, error) {
+	_, stats, err := GetWithStats[struct{}](ctx, policy, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, run(ctx)
+	})
+
+	return stats, err
+}
+
+// GetWithStats is Get also returning the statistics of the call.
+func GetWithStats[T any](ctx context.Context, policy Policy, run RunFunc[T]) (T, Stats, error) {
+	var stats Stats
+	value, err := Get[T](ctx, policy.With(func(config *internal.RetryerConfig) {
+		config.Stats = &stats
+	}), run)
+
+	return value, stats, err
+}
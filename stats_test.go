@@ -0,0 +1,113 @@
+package again_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+)
+
+func TestGetWithStats(t *testing.T) {
+	policy := again.ConstantDelay(5*time.Millisecond, time.Second)
+
+	t.Run("success after retries", func(t *testing.T) {
+		called := 0
+		failure := errors.New("not yet")
+
+		value, stats, err := again.GetWithStats(context.Background(), policy, func(ctx context.Context) (int, error) {
+			called++
+			if called < 3 {
+				return 0, failure
+			}
+			time.Sleep(2 * time.Millisecond)
+			return called, nil
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, 3, value)
+		require.Equal(t, 3, stats.Attempts)
+		require.Equal(t, again.StopSucceeded, stats.StopReason)
+		require.GreaterOrEqual(t, stats.Sleep, 10*time.Millisecond)
+		require.GreaterOrEqual(t, stats.Elapsed, stats.Sleep+2*time.Millisecond)
+		require.Len(t, stats.PerAttempt, 3)
+		require.Equal(t, failure, stats.PerAttempt[0].Err)
+		require.NoError(t, stats.PerAttempt[2].Err)
+		require.GreaterOrEqual(t, stats.PerAttempt[2].Duration, 2*time.Millisecond)
+		require.Zero(t, stats.RateLimitWait)
+	})
+
+	t.Run("stop reasons", func(t *testing.T) {
+		failing := func(ctx context.Context) error {
+			return errors.New("failing")
+		}
+		cancelled, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		for name, test := range map[string]struct {
+			ctx      context.Context
+			policy   again.Policy
+			run      func(ctx context.Context) error
+			expected again.StopReason
+		}{
+			"permanent error": {
+				ctx:    context.Background(),
+				policy: policy,
+				run: func(ctx context.Context) error {
+					return again.NewPermanentError(errors.New("invalid"))
+				},
+				expected: again.StopPermanentError,
+			},
+			"not retryable": {
+				ctx:      context.Background(),
+				policy:   policy.With(again.WithRetryIf(func(err error) bool { return false })),
+				run:      failing,
+				expected: again.StopNotRetryable,
+			},
+			"max attempts": {
+				ctx:      context.Background(),
+				policy:   policy.With(again.WithMaxAttempts(2)),
+				run:      failing,
+				expected: again.StopMaxAttempts,
+			},
+			"policy gave up": {
+				ctx:      context.Background(),
+				policy:   again.ConstantDelay(time.Millisecond, 5*time.Millisecond),
+				run:      failing,
+				expected: again.StopPolicy,
+			},
+			"context done": {
+				ctx:      cancelled,
+				policy:   policy,
+				run:      failing,
+				expected: again.StopContextDone,
+			},
+		} {
+			stats, err := again.DoWithStats(test.ctx, test.policy, test.run)
+
+			require.Error(t, err, name)
+			require.Equal(t, test.expected, stats.StopReason, name)
+		}
+	})
+
+	t.Run("rate limiter waits are counted apart from sleep", func(t *testing.T) {
+		limiter := again.NewRateLimiter(100, 1)
+		called := 0
+
+		stats, err := again.DoWithStats(context.Background(), policy.With(again.WithRateLimiter(limiter)),
+			func(ctx context.Context) error {
+				called++
+				if called < 3 {
+					return errors.New("not yet")
+				}
+				return nil
+			})
+
+		require.NoError(t, err)
+		require.Greater(t, stats.RateLimitWait, time.Duration(0))
+		require.GreaterOrEqual(t, stats.Sleep, 10*time.Millisecond)
+	})
+}
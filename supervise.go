@@ -0,0 +1,174 @@
+package again
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jdvr/go-again/internal"
+)
+
+const defaultStableAfter = time.Minute
+
+// ErrRestartIntensity is returned by Supervise when the function restarts more often than allowed.
+var ErrRestartIntensity = errors.New("again: restart intensity exceeded")
+
+// SupervisorState is the state of a supervised function.
+type SupervisorState int
+
+const (
+	// SupervisorRunning means the function is running
+	SupervisorRunning SupervisorState = iota
+	// SupervisorRestarting means the function failed and is waiting for the backoff before restarting
+	SupervisorRestarting
+	// SupervisorStopped means Supervise is returning
+	SupervisorStopped
+)
+
+func (s SupervisorState) String() string {
+	switch s {
+	case SupervisorRunning:
+		return "running"
+	case SupervisorRestarting:
+		return "restarting"
+	case SupervisorStopped:
+		return "stopped"
+	default:
+		return fmt.Sprintf("SupervisorState(%d)", int(s))
+	}
+}
+
+// SupervisorEvent describes a state transition of a supervised function.
+type SupervisorEvent struct {
+	State SupervisorState
+	// Restarts is the number of restarts so far
+	Restarts int
+	// Err is the error that made the function restart or stop
+	Err error
+	// Delay before restarting
+	Delay time.Duration
+}
+
+// SupervisorOption customizes Supervise.
+type SupervisorOption func(config *supervisorConfig)
+
+type supervisorConfig struct {
+	stableAfter  time.Duration
+	maxRestarts  int
+	within       time.Duration
+	onTransition func(event SupervisorEvent)
+}
+
+// WithStableAfter resets the backoff when the function ran for at least d before failing, one minute by default.
+func WithStableAfter(d time.Duration) SupervisorOption {
+	return func(config *supervisorConfig) {
+		config.stableAfter = d
+	}
+}
+
+// WithRestartIntensity stops supervising with ErrRestartIntensity when the function restarts
+// more than maxRestarts times within the given period.
+func WithRestartIntensity(maxRestarts int, within time.Duration) SupervisorOption {
+	return func(config *supervisorConfig) {
+		config.maxRestarts = maxRestarts
+		config.within = within
+	}
+}
+
+// OnTransition calls fn on every state transition of the supervised function.
+func OnTransition(fn func(event SupervisorEvent)) SupervisorOption {
+	return func(config *supervisorConfig) {
+		config.onTransition = fn
+	}
+}
+
+// Supervise runs fn and restarts it with policy delays whenever it fails or panics, panics being
+// converted into PanicError. It returns nil when fn returns nil, the error of a permanent error,
+// the context error when ctx ends, or the last error when the policy gives up.
+// The policy timeout only counts the time spent waiting for restarts, not the time fn ran, and the
+// timeout and delays start over after a run lasting longer than the stable period.
+func Supervise(ctx context.Context, policy Policy, fn func(ctx context.Context) error, options ...SupervisorOption) error {
+	config := supervisorConfig{stableAfter: defaultStableAfter}
+	for _, option := range options {
+		option(&config)
+	}
+	transition := func(event SupervisorEvent) {
+		if config.onTransition != nil {
+			config.onTransition(event)
+		}
+	}
+	stop := func(restarts int, err error) error {
+		transition(SupervisorEvent{State: SupervisorStopped, Restarts: restarts, Err: err})
+		return err
+	}
+
+	clock := &downtimeClock{}
+	calculator := policy.NewTicksCalculator(clock)
+	calculator.Reset()
+	var restartedAt []time.Time
+	for restarts := 0; ; restarts++ {
+		transition(SupervisorEvent{State: SupervisorRunning, Restarts: restarts})
+		startedAt := time.Now()
+		var err error
+		internal.RecoverPanic(&err, func() {
+			err = fn(ctx)
+		})
+		if err == nil {
+			return stop(restarts, nil)
+		}
+		if cerr := ctx.Err(); cerr != nil {
+			return stop(restarts, cerr)
+		}
+		var permanent *internal.PermanentError
+		if errors.As(err, &permanent) {
+			return stop(restarts, permanent.Err)
+		}
+		if !policy.Retryable(err) {
+			return stop(restarts, err)
+		}
+
+		now := time.Now()
+		clock.running += now.Sub(startedAt)
+		if now.Sub(startedAt) >= config.stableAfter {
+			calculator.Reset()
+		}
+		if config.maxRestarts > 0 {
+			restartedAt = append(recentRestarts(restartedAt, now.Add(-config.within)), now)
+			if len(restartedAt) > config.maxRestarts {
+				return stop(restarts, fmt.Errorf("%w: %w", ErrRestartIntensity, err))
+			}
+		}
+		tick := calculator.Next()
+		if tick.Stop {
+			return stop(restarts, err)
+		}
+
+		transition(SupervisorEvent{State: SupervisorRestarting, Restarts: restarts, Err: err, Delay: tick.Next})
+		timer := time.NewTimer(tick.Next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return stop(restarts, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// downtimeClock is the system clock stopped while the supervised function runs.
+type downtimeClock struct {
+	running time.Duration
+}
+
+func (c *downtimeClock) Now() time.Time {
+	return time.Now().Add(-c.running)
+}
+
+// recentRestarts drops the restarts older than since.
+func recentRestarts(restarts []time.Time, since time.Time) []time.Time {
+	for len(restarts) > 0 && restarts[0].Before(since) {
+		restarts = restarts[1:]
+	}
+
+	return restarts
+}
@@ -0,0 +1,145 @@
+package again_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+)
+
+func TestSupervise(t *testing.T) {
+	policy := again.ConstantDelay(time.Millisecond, time.Minute)
+
+	t.Run("restarts on failures and panics until the function returns", func(t *testing.T) {
+		runs := 0
+		var states []again.SupervisorState
+		var restartErrs []error
+
+		err := again.Supervise(context.Background(), policy, func(ctx context.Context) error {
+			runs++
+			switch runs {
+			case 1:
+				return errors.New("connection lost")
+			case 2:
+				panic("nil map")
+			default:
+				return nil
+			}
+		}, again.OnTransition(func(event again.SupervisorEvent) {
+			states = append(states, event.State)
+			if event.State == again.SupervisorRestarting {
+				restartErrs = append(restartErrs, event.Err)
+			}
+		}))
+
+		require.NoError(t, err)
+		require.Equal(t, 3, runs)
+		require.Equal(t, []again.SupervisorState{
+			again.SupervisorRunning, again.SupervisorRestarting,
+			again.SupervisorRunning, again.SupervisorRestarting,
+			again.SupervisorRunning, again.SupervisorStopped,
+		}, states)
+		require.EqualError(t, restartErrs[0], "connection lost")
+		var panicErr *again.PanicError
+		require.ErrorAs(t, restartErrs[1], &panicErr)
+		require.Equal(t, "nil map", panicErr.Value)
+		require.Contains(t, string(panicErr.Stack), "supervise_test.go")
+	})
+
+	t.Run("stable runs reset the backoff", func(t *testing.T) {
+		exponential := again.ExponentialBackoff(again.BackoffConfiguration{
+			InitialInterval:      time.Millisecond,
+			IntervalMultiplier:   10,
+			Timeout:              time.Minute,
+			DisableRandomization: true,
+		})
+		runs := 0
+		var delays []time.Duration
+
+		_ = again.Supervise(context.Background(), exponential, func(ctx context.Context) error {
+			runs++
+			if runs == 3 {
+				time.Sleep(20 * time.Millisecond)
+			}
+			if runs == 4 {
+				return again.NewPermanentError(errors.New("done"))
+			}
+			return errors.New("failing")
+		}, again.WithStableAfter(10*time.Millisecond), again.OnTransition(func(event again.SupervisorEvent) {
+			if event.State == again.SupervisorRestarting {
+				delays = append(delays, event.Delay)
+			}
+		}))
+
+		require.Equal(t, []time.Duration{time.Millisecond, 10 * time.Millisecond, time.Millisecond}, delays)
+	})
+
+	t.Run("running time doesn't use the policy timeout", func(t *testing.T) {
+		runs := 0
+
+		err := again.Supervise(context.Background(), again.ConstantDelay(time.Millisecond, 30*time.Millisecond),
+			func(ctx context.Context) error {
+				runs++
+				if runs == 6 {
+					return nil
+				}
+				time.Sleep(20 * time.Millisecond)
+				return errors.New("connection lost")
+			})
+
+		require.NoError(t, err)
+		require.Equal(t, 6, runs)
+	})
+
+	t.Run("restart intensity limit", func(t *testing.T) {
+		runs := 0
+
+		err := again.Supervise(context.Background(), policy, func(ctx context.Context) error {
+			runs++
+			return errors.New("crashing")
+		}, again.WithRestartIntensity(5, time.Minute))
+
+		require.ErrorIs(t, err, again.ErrRestartIntensity)
+		require.ErrorContains(t, err, "crashing")
+		require.Equal(t, 6, runs)
+	})
+
+	t.Run("stops when the context ends", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := again.Supervise(ctx, policy, func(ctx context.Context) error {
+			<-ctx.Done()
+			return errors.New("consumer closed")
+		})
+
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("stops when the policy gives up", func(t *testing.T) {
+		err := again.Supervise(context.Background(), again.ConstantDelay(time.Millisecond, 10*time.Millisecond),
+			func(ctx context.Context) error {
+				return errors.New("crashing")
+			})
+
+		require.EqualError(t, err, "crashing")
+	})
+
+	t.Run("stops on errors the policy doesn't retry", func(t *testing.T) {
+		runs := 0
+
+		err := again.Supervise(context.Background(), policy.With(again.WithRetryIf(func(err error) bool {
+			return err.Error() == "connection lost"
+		})), func(ctx context.Context) error {
+			runs++
+			return errors.New("invalid config")
+		})
+
+		require.EqualError(t, err, "invalid config")
+		require.Equal(t, 1, runs)
+	})
+}
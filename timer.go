@@ -5,9 +5,10 @@ import (
 	"time"
 )
 
-type systemClock struct{}
+// SystemClock reads the current time from the operating system.
+type SystemClock struct{}
 
-func (sc systemClock) Now() time.Time {
+func (sc SystemClock) Now() time.Time {
 	return time.Now()
 }
 
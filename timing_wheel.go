@@ -0,0 +1,217 @@
+package again
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jdvr/go-again/internal"
+)
+
+// Timer waits for the delay of a tick between retries.
+type Timer = internal.Timer
+
+const (
+	wheelSlotBits = 6
+	wheelSlots    = 1 << wheelSlotBits
+	wheelSlotMask = wheelSlots - 1
+	wheelLevels   = 4
+
+	defaultWheelTick = time.Millisecond
+)
+
+// TimingWheel is a hierarchical timing wheel shared by many timers. It keeps pending timers in linked
+// slots advanced by a single ticker, so a large number of retries waiting at the same time don't need
+// a runtime timer each. Delays are rounded up to the wheel tick.
+type TimingWheel struct {
+	mu      sync.Mutex
+	tick    time.Duration
+	startAt time.Time
+	now     uint64
+	levels  [wheelLevels][wheelSlots]wheelSlot
+
+	ticker *time.Ticker
+	done   chan struct{}
+	once   sync.Once
+}
+
+type wheelSlot struct {
+	head *wheelTimer
+}
+
+// NewTimingWheel starts a timing wheel advancing every tick, one millisecond is used when tick is 0.
+// The wheel reaches 64^4 ticks ahead, longer delays are handled by re-scheduling them when they get closer.
+func NewTimingWheel(tick time.Duration) *TimingWheel {
+	if tick <= 0 {
+		tick = defaultWheelTick
+	}
+	w := &TimingWheel{
+		tick:    tick,
+		startAt: time.Now(),
+		ticker:  time.NewTicker(tick),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+
+	return w
+}
+
+// WithTimingWheel makes the retryer wait on wheel instead of a dedicated runtime timer.
+func WithTimingWheel(wheel *TimingWheel) Option {
+	return func(config *internal.RetryerConfig) {
+		config.Timer = wheel.NewTimer()
+	}
+}
+
+// NewTimer returns a Timer scheduled on the wheel, like other timers it must not be used concurrently.
+func (w *TimingWheel) NewTimer() Timer {
+	return &wheelTimer{
+		wheel: w,
+		c:     make(chan time.Time, 1),
+	}
+}
+
+// Stop stops advancing the wheel, pending timers never fire.
+func (w *TimingWheel) Stop() {
+	w.once.Do(func() {
+		w.ticker.Stop()
+		close(w.done)
+	})
+}
+
+func (w *TimingWheel) run() {
+	for {
+		select {
+		case <-w.done:
+			return
+		case now := <-w.ticker.C:
+			w.advanceTo(now)
+		}
+	}
+}
+
+// advanceTo moves the wheel until the given time, cascading timers from upper levels
+// whenever a lower level completes a rotation, and firing the expired ones.
+func (w *TimingWheel) advanceTo(at time.Time) {
+	target := uint64(at.Sub(w.startAt) / w.tick)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for w.now < target {
+		w.now++
+		for level := 1; level < wheelLevels; level++ {
+			if w.now&(uint64(1)<<(wheelSlotBits*level)-1) != 0 {
+				break
+			}
+			slot := &w.levels[level][(w.now>>(wheelSlotBits*level))&wheelSlotMask]
+			timers := slot.head
+			slot.head = nil
+			for timer := timers; timer != nil; {
+				next := timer.next
+				timer.prev, timer.next, timer.slot = nil, nil, nil
+				w.schedule(timer, at)
+				timer = next
+			}
+		}
+
+		slot := &w.levels[0][w.now&wheelSlotMask]
+		for slot.head != nil {
+			timer := slot.head
+			w.remove(timer)
+			timer.fire(at)
+		}
+	}
+}
+
+// schedule adds the timer to the slot of the level matching its remaining ticks, fires it if expired.
+// It must be called holding the lock.
+func (w *TimingWheel) schedule(timer *wheelTimer, at time.Time) {
+	if timer.expiry <= w.now {
+		timer.fire(at)
+		return
+	}
+
+	delta := timer.expiry - w.now
+	level := 0
+	for level < wheelLevels-1 && delta >= uint64(1)<<(wheelSlotBits*(level+1)) {
+		level++
+	}
+	expiry := timer.expiry
+	if maxDelta := uint64(1)<<(wheelSlotBits*wheelLevels) - 1; delta > maxDelta {
+		// out of range timers wait in the furthest slot and are re-scheduled once it cascades
+		expiry = w.now + maxDelta
+	}
+
+	slot := &w.levels[level][(expiry>>(wheelSlotBits*level))&wheelSlotMask]
+	timer.slot = slot
+	timer.next = slot.head
+	if slot.head != nil {
+		slot.head.prev = timer
+	}
+	slot.head = timer
+}
+
+// remove unlinks the timer from its slot, it must be called holding the lock.
+func (w *TimingWheel) remove(timer *wheelTimer) {
+	if timer.slot == nil {
+		return
+	}
+	if timer.prev != nil {
+		timer.prev.next = timer.next
+	} else {
+		timer.slot.head = timer.next
+	}
+	if timer.next != nil {
+		timer.next.prev = timer.prev
+	}
+	timer.prev, timer.next, timer.slot = nil, nil, nil
+}
+
+type wheelTimer struct {
+	wheel  *TimingWheel
+	c      chan time.Time
+	expiry uint64
+
+	slot       *wheelSlot
+	prev, next *wheelTimer
+}
+
+var _ Timer = &wheelTimer{}
+
+func (t *wheelTimer) Start(tick internal.Tick) {
+	w := t.wheel
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.remove(t)
+	select {
+	case <-t.c:
+	default:
+	}
+
+	ticks := uint64((tick.Next + w.tick - 1) / w.tick)
+	if tick.Next <= 0 {
+		ticks = 0
+	}
+	t.expiry = w.now + ticks
+	w.schedule(t, time.Now())
+}
+
+func (t *wheelTimer) Wait() <-chan time.Time {
+	return t.c
+}
+
+// Stop is called when the timer is not used anymore and resources may be freed.
+func (t *wheelTimer) Stop() {
+	t.wheel.mu.Lock()
+	defer t.wheel.mu.Unlock()
+
+	t.wheel.remove(t)
+}
+
+func (t *wheelTimer) fire(at time.Time) {
+	select {
+	case t.c <- at:
+	default:
+	}
+}
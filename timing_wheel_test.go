@@ -0,0 +1,136 @@
+package again_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+)
+
+func TestTimingWheel(t *testing.T) {
+	t.Run("timer fires after the delay", func(t *testing.T) {
+		wheel := again.NewTimingWheel(time.Millisecond)
+		defer wheel.Stop()
+		timer := wheel.NewTimer()
+
+		startAt := time.Now()
+		timer.Start(again.Tick{Next: 20 * time.Millisecond})
+		<-timer.Wait()
+
+		require.GreaterOrEqual(t, time.Since(startAt), 19*time.Millisecond)
+	})
+
+	t.Run("timers beyond the first level are cascaded", func(t *testing.T) {
+		wheel := again.NewTimingWheel(100 * time.Microsecond)
+		defer wheel.Stop()
+		timer := wheel.NewTimer()
+
+		startAt := time.Now()
+		timer.Start(again.Tick{Next: 30 * time.Millisecond})
+		<-timer.Wait()
+
+		require.GreaterOrEqual(t, time.Since(startAt), 29*time.Millisecond)
+	})
+
+	t.Run("timers fire in order", func(t *testing.T) {
+		wheel := again.NewTimingWheel(time.Millisecond)
+		defer wheel.Stop()
+
+		var (
+			mu    sync.Mutex
+			fired []int
+			wg    sync.WaitGroup
+		)
+		for _, delay := range []int{30, 10, 20} {
+			delay := delay
+			timer := wheel.NewTimer()
+			timer.Start(again.Tick{Next: time.Duration(delay) * time.Millisecond})
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-timer.Wait()
+				mu.Lock()
+				defer mu.Unlock()
+				fired = append(fired, delay)
+			}()
+		}
+		wg.Wait()
+
+		require.Equal(t, []int{10, 20, 30}, fired)
+	})
+
+	t.Run("stopped timer doesn't fire", func(t *testing.T) {
+		wheel := again.NewTimingWheel(time.Millisecond)
+		defer wheel.Stop()
+		timer := wheel.NewTimer()
+
+		timer.Start(again.Tick{Next: 5 * time.Millisecond})
+		timer.Stop()
+
+		select {
+		case <-timer.Wait():
+			t.Fatal("stopped timer fired")
+		case <-time.After(20 * time.Millisecond):
+		}
+	})
+
+	t.Run("retryer waits on the wheel", func(t *testing.T) {
+		wheel := again.NewTimingWheel(time.Millisecond)
+		defer wheel.Stop()
+		policy := again.ConstantDelay(2*time.Millisecond, time.Second, again.WithTimingWheel(wheel))
+
+		called := 0
+		err := again.Do(context.Background(), policy, func(ctx context.Context) error {
+			called++
+			if called < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 3, called)
+	})
+}
+
+// BenchmarkTimers compares the cost of many retries waiting at the same time on runtime timers and on a timing wheel.
+func BenchmarkTimers(b *testing.B) {
+	for _, concurrent := range []int{10_000, 100_000} {
+		b.Run(fmt.Sprintf("default/%d", concurrent), func(b *testing.B) {
+			benchmarkConcurrentRetries(b, concurrent, again.ConstantDelay(10*time.Millisecond, time.Minute))
+		})
+		b.Run(fmt.Sprintf("wheel/%d", concurrent), func(b *testing.B) {
+			wheel := again.NewTimingWheel(time.Millisecond)
+			defer wheel.Stop()
+			benchmarkConcurrentRetries(b, concurrent, again.ConstantDelay(10*time.Millisecond, time.Minute, again.WithTimingWheel(wheel)))
+		})
+	}
+}
+
+func benchmarkConcurrentRetries(b *testing.B, concurrent int, policy again.Policy) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		wg.Add(concurrent)
+		for c := 0; c < concurrent; c++ {
+			go func() {
+				defer wg.Done()
+				called := 0
+				_ = again.Do(context.Background(), policy, func(ctx context.Context) error {
+					called++
+					if called < 2 {
+						return errors.New("retry once")
+					}
+					return nil
+				})
+			}()
+		}
+		wg.Wait()
+	}
+}
@@ -0,0 +1,33 @@
+package again
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnacceptableResult is returned along with the last value when the retry gives up because
+// the operation kept succeeding with values that failed the acceptance predicate.
+var ErrUnacceptableResult = errors.New("again: unacceptable result")
+
+// Until wraps run so a successful value failing acceptable is retried as if run had failed.
+// It can be used with any retryer, policy or helper taking a RunFunc.
+func Until[T any](run RunFunc[T], acceptable func(value T) bool) RunFunc[T] {
+	return func(ctx context.Context) (T, error) {
+		value, err := run(ctx)
+		if err != nil {
+			return value, err
+		}
+		if !acceptable(value) {
+			return value, ErrUnacceptableResult
+		}
+
+		return value, nil
+	}
+}
+
+// RetryUntil use WithExponentialBackoff to retry the run function until it returns an acceptable value,
+// a permanent error or timeout is reached.
+// it might return the last value with ErrUnacceptableResult, the last function run error or a context cancelled error.
+func RetryUntil[T any](ctx context.Context, run RunFunc[T], acceptable func(value T) bool) (T, error) {
+	return Retry[T](ctx, Until(run, acceptable))
+}
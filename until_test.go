@@ -0,0 +1,75 @@
+package again_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+)
+
+func TestUntil(t *testing.T) {
+	t.Run("given function is called until value is acceptable", func(t *testing.T) {
+		statuses := []string{"PENDING", "PENDING", "DONE"}
+		called := 0
+		policy := again.ConstantDelay(time.Millisecond, time.Second)
+
+		status, err := again.Get[string](context.Background(), policy, again.Until(func(ctx context.Context) (string, error) {
+			status := statuses[called]
+			called++
+			return status, nil
+		}, func(status string) bool {
+			return status == "DONE"
+		}))
+		require.NoError(t, err)
+
+		require.Equal(t, "DONE", status)
+		require.Equal(t, 3, called)
+	})
+
+	t.Run("last value is returned when the retry gives up", func(t *testing.T) {
+		policy := again.ConstantDelay(time.Millisecond, 5*time.Millisecond)
+
+		status, err := again.Get[string](context.Background(), policy, again.Until(func(ctx context.Context) (string, error) {
+			return "PENDING", nil
+		}, func(status string) bool {
+			return status == "DONE"
+		}))
+
+		require.ErrorIs(t, err, again.ErrUnacceptableResult)
+		require.Equal(t, "PENDING", status)
+	})
+
+	t.Run("errors are returned as they are", func(t *testing.T) {
+		expectedErr := errors.New("whatever")
+		policy := again.ConstantDelay(time.Millisecond, time.Second)
+
+		_, err := again.Get[string](context.Background(), policy, again.Until(func(ctx context.Context) (string, error) {
+			return "", again.NewPermanentError(expectedErr)
+		}, func(status string) bool {
+			return true
+		}))
+
+		require.ErrorIs(t, err, expectedErr)
+		require.NotErrorIs(t, err, again.ErrUnacceptableResult)
+	})
+}
+
+func TestRetryUntil(t *testing.T) {
+	t.Run("given function is called until value is acceptable", func(t *testing.T) {
+		called := 0
+
+		value, err := again.RetryUntil[int](context.Background(), func(ctx context.Context) (int, error) {
+			called++
+			return called, nil
+		}, func(value int) bool {
+			return value == 2
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 2, value)
+	})
+}
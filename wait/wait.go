@@ -0,0 +1,155 @@
+// Package wait blocks until a TCP port, an HTTP endpoint, a file or a command is ready, checking it
+// with go-again policies. On timeout the returned error describes the last observed state.
+package wait
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/jdvr/go-again"
+)
+
+const maxReportedOutput = 256
+
+// HTTPMatcher checks a response, returning an error describing why it isn't the expected one.
+type HTTPMatcher func(response *http.Response, body []byte) error
+
+// StatusIs matches responses with any of the given status codes.
+func StatusIs(codes ...int) HTTPMatcher {
+	return func(response *http.Response, _ []byte) error {
+		for _, code := range codes {
+			if response.StatusCode == code {
+				return nil
+			}
+		}
+		return fmt.Errorf("status %s", response.Status)
+	}
+}
+
+// BodyContains matches responses whose body contains text.
+func BodyContains(text string) HTTPMatcher {
+	return func(_ *http.Response, body []byte) error {
+		if bytes.Contains(body, []byte(text)) {
+			return nil
+		}
+		return fmt.Errorf("body %q doesn't contain %q", truncate(body), text)
+	}
+}
+
+// BodyMatches matches responses whose body matches re.
+func BodyMatches(re *regexp.Regexp) HTTPMatcher {
+	return func(_ *http.Response, body []byte) error {
+		if re.Match(body) {
+			return nil
+		}
+		return fmt.Errorf("body %q doesn't match %q", truncate(body), re)
+	}
+}
+
+// WaitForTCP waits until address accepts TCP connections.
+func WaitForTCP(ctx context.Context, policy again.Policy, address string) error {
+	return waitFor(ctx, policy, "tcp "+address, func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+}
+
+// WaitForHTTP waits until a GET to url returns a response accepted by every matcher,
+// when no matcher is given any 2xx status is expected. http.DefaultClient is used when client is nil.
+func WaitForHTTP(ctx context.Context, policy again.Policy, client *http.Client, url string, matchers ...HTTPMatcher) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if len(matchers) == 0 {
+		matchers = []HTTPMatcher{successful}
+	}
+
+	return waitFor(ctx, policy, "http "+url, func(ctx context.Context) error {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return again.NewPermanentError(err)
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			return err
+		}
+		for _, matcher := range matchers {
+			if err := matcher(response, body); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// WaitForFile waits until path exists.
+func WaitForFile(ctx context.Context, policy again.Policy, path string) error {
+	return waitFor(ctx, policy, "file "+path, func(ctx context.Context) error {
+		_, err := os.Stat(path)
+		return err
+	})
+}
+
+// WaitForCommand waits until the command exits successfully.
+func WaitForCommand(ctx context.Context, policy again.Policy, name string, args ...string) error {
+	return waitFor(ctx, policy, "command "+strings.Join(append([]string{name}, args...), " "), func(ctx context.Context) error {
+		output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+		if err != nil {
+			if len(output) > 0 {
+				return fmt.Errorf("%w: %q", err, truncate(output))
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// waitFor polls check until it returns nil, check errors describe the observed state.
+func waitFor(ctx context.Context, policy again.Policy, target string, check func(ctx context.Context) error) error {
+	err := again.Poll(ctx, policy, func(ctx context.Context) (bool, error) {
+		if err := check(ctx); err != nil {
+			return false, err
+		}
+		return true, nil
+	}, again.TolerateErrors())
+	if err != nil {
+		return fmt.Errorf("wait: %s not ready: %w", target, err)
+	}
+
+	return nil
+}
+
+func successful(response *http.Response, _ []byte) error {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+
+	return fmt.Errorf("status %s", response.Status)
+}
+
+func truncate(data []byte) string {
+	text := strings.TrimSpace(string(data))
+	if len(text) > maxReportedOutput {
+		return text[:maxReportedOutput] + "..."
+	}
+
+	return text
+}
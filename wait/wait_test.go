@@ -0,0 +1,145 @@
+package wait_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jdvr/go-again"
+	"github.com/jdvr/go-again/wait"
+)
+
+var (
+	testPolicy  = again.ConstantDelay(5*time.Millisecond, 2*time.Second)
+	shortPolicy = again.ConstantDelay(time.Millisecond, 20*time.Millisecond)
+)
+
+func TestWaitForTCP(t *testing.T) {
+	t.Run("port accepts connections", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+
+		require.NoError(t, wait.WaitForTCP(context.Background(), testPolicy, listener.Addr().String()))
+	})
+
+	t.Run("port opened later", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := listener.Addr().String()
+		require.NoError(t, listener.Close())
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			listener, err := net.Listen("tcp", address)
+			if err == nil {
+				t.Cleanup(func() { listener.Close() })
+			}
+		}()
+
+		require.NoError(t, wait.WaitForTCP(context.Background(), testPolicy, address))
+	})
+
+	t.Run("timeout describes the last error", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := listener.Addr().String()
+		require.NoError(t, listener.Close())
+
+		err = wait.WaitForTCP(context.Background(), shortPolicy, address)
+
+		require.ErrorIs(t, err, again.ErrConditionNotMet)
+		require.ErrorContains(t, err, "tcp "+address+" not ready")
+		require.ErrorContains(t, err, "connection refused")
+	})
+}
+
+func TestWaitForHTTP(t *testing.T) {
+	t.Run("endpoint becomes healthy", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		}))
+		defer server.Close()
+
+		err := wait.WaitForHTTP(context.Background(), testPolicy, nil, server.URL)
+		require.NoError(t, err)
+
+		require.EqualValues(t, 3, requests.Load())
+	})
+
+	t.Run("status and body matchers", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		}))
+		defer server.Close()
+
+		err := wait.WaitForHTTP(context.Background(), testPolicy, server.Client(), server.URL,
+			wait.StatusIs(http.StatusOK, http.StatusAccepted),
+			wait.BodyContains(`"ok"`),
+			wait.BodyMatches(regexp.MustCompile(`"status":\s*"ok"`)),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("timeout describes the last response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"status":"starting"}`))
+		}))
+		defer server.Close()
+
+		err := wait.WaitForHTTP(context.Background(), shortPolicy, nil, server.URL, wait.BodyContains(`"ok"`))
+
+		require.ErrorIs(t, err, again.ErrConditionNotMet)
+		require.ErrorContains(t, err, `starting`)
+	})
+}
+
+func TestWaitForFile(t *testing.T) {
+	t.Run("file created later", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "ready")
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			_ = os.WriteFile(path, nil, 0o600)
+		}()
+
+		require.NoError(t, wait.WaitForFile(context.Background(), testPolicy, path))
+	})
+
+	t.Run("timeout describes the missing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "ready")
+
+		err := wait.WaitForFile(context.Background(), shortPolicy, path)
+
+		require.ErrorIs(t, err, os.ErrNotExist)
+		require.ErrorContains(t, err, "file "+path+" not ready")
+	})
+}
+
+func TestWaitForCommand(t *testing.T) {
+	t.Run("command succeeds", func(t *testing.T) {
+		require.NoError(t, wait.WaitForCommand(context.Background(), testPolicy, "true"))
+	})
+
+	t.Run("timeout describes the last output", func(t *testing.T) {
+		err := wait.WaitForCommand(context.Background(), shortPolicy, "sh", "-c", "echo database is starting; exit 3")
+
+		require.ErrorIs(t, err, again.ErrConditionNotMet)
+		require.ErrorContains(t, err, "exit status 3")
+		require.ErrorContains(t, err, "database is starting")
+	})
+}